@@ -2,7 +2,10 @@ package main
 
 import (
 	"flag"
+	"fmt"
 	"log"
+	"os"
+	"os/user"
 
 	ui "github.com/gizak/termui/v3"
 	"github.com/tinyzimmer/kubeconsole/pkg/k8sutils"
@@ -19,6 +22,8 @@ var debug bool
 var listen bool
 var incluster bool
 var serverkey string
+var record term.RecordOptions
+var auditUser string
 
 func init() {
 
@@ -27,14 +32,31 @@ func init() {
 	flag.StringVar(&serverkey, "keyfile", "", `A pre-generated server key file for SSH
 		If you do not supply this, one will be generated`)
 	flag.BoolVar(&incluster, "cluster", false, "Use in-cluster k8s config")
+	flag.StringVar(&record.Dir, "record", "", "Record exec sessions to asciicast files in this directory")
+	flag.BoolVar(&record.Input, "record-input", false, "Record what is typed into exec sessions as well")
+	flag.StringVar(&auditUser, "user", "", `Who is using the console, for the audit log
+		Defaults to the local user, the SSH server sets it for its sessions`)
 	flag.Parse()
 
 }
 
 func main() {
 
+	if flag.Arg(0) == "replay" {
+		replay(flag.Args()[1:])
+		return
+	}
+
 	if listen {
-		s, err := server.New(incluster, serverkey)
+		// consoles started for SSH sessions record the same way
+		consoleArgs := make([]string, 0)
+		if record.Dir != "" {
+			consoleArgs = append(consoleArgs, "-record", record.Dir)
+		}
+		if record.Input {
+			consoleArgs = append(consoleArgs, "-record-input")
+		}
+		s, err := server.New(incluster, serverkey, consoleArgs)
 		if err != nil {
 			log.Fatal(err)
 		}
@@ -52,9 +74,39 @@ func main() {
 		log.Fatalf("failed to initialize termui: %v", err)
 	}
 	defer ui.Close()
-	controller = term.New(factory, debug)
+	if auditUser == "" {
+		auditUser = "unknown user"
+		if u, err := user.Current(); err == nil {
+			auditUser = u.Username
+		}
+	}
+	controller = term.New(factory, debug, record, auditUser)
 	if err = controller.Run(); err != nil {
 		log.Fatal(err)
 	}
 
 }
+
+// replay plays back a recorded exec session
+func replay(args []string) {
+	flags := flag.NewFlagSet("replay", flag.ExitOnError)
+	speed := flags.Float64("speed", 1, "Play back at this many times the recorded speed")
+	flags.Usage = func() {
+		fmt.Fprintf(flags.Output(), "Usage: %s replay [-speed N] <file.cast>\n", os.Args[0])
+		flags.PrintDefaults()
+	}
+	flags.Parse(args)
+	if flags.NArg() != 1 {
+		flags.Usage()
+		os.Exit(2)
+	}
+
+	if err := ui.Init(); err != nil {
+		log.Fatalf("failed to initialize termui: %v", err)
+	}
+	err := term.Replay(flags.Arg(0), *speed)
+	ui.Close()
+	if err != nil {
+		log.Fatal(err)
+	}
+}
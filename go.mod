@@ -10,6 +10,7 @@ require (
 	github.com/json-iterator/go v1.1.7 // indirect
 	github.com/kr/pretty v0.1.0 // indirect
 	github.com/kr/pty v1.1.8
+	github.com/mattn/go-runewidth v0.0.2
 	github.com/spf13/pflag v1.0.3 // indirect
 	golang.org/x/crypto v0.0.0-20190701094942-4def268fd1a4
 	golang.org/x/net v0.0.0-20190813141303-74dc4d7220e7 // indirect
@@ -24,4 +25,5 @@ require (
 	k8s.io/client-go v0.0.0-20190718183610-8e956561bbf5
 	k8s.io/klog v0.4.0 // indirect
 	k8s.io/utils v0.0.0-20190809000727-6c36bc71fc4a // indirect
+	sigs.k8s.io/yaml v1.1.0
 )
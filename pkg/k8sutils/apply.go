@@ -0,0 +1,241 @@
+package k8sutils
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	utilyaml "k8s.io/apimachinery/pkg/util/yaml"
+	"k8s.io/client-go/dynamic"
+	"sigs.k8s.io/yaml"
+)
+
+// manifestExtensions are the files read from a directory of manifests
+var manifestExtensions = []string{".yaml", ".yml", ".json"}
+
+// ReadManifests reads every object in a manifest file, or in the manifest
+// files directly inside a directory in name order. Files can hold several
+// YAML documents, and List objects are expanded into their items.
+func ReadManifests(path string) ([]*unstructured.Unstructured, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+	files := []string{path}
+	if info.IsDir() {
+		entries, err := ioutil.ReadDir(path)
+		if err != nil {
+			return nil, err
+		}
+		files = make([]string, 0)
+		for _, entry := range entries {
+			if !entry.IsDir() && isManifestFile(entry.Name()) {
+				files = append(files, filepath.Join(path, entry.Name()))
+			}
+		}
+	}
+
+	objects := make([]*unstructured.Unstructured, 0)
+	for _, file := range files {
+		fileObjects, err := readManifestFile(file)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %v", file, err)
+		}
+		objects = append(objects, fileObjects...)
+	}
+	if len(objects) == 0 {
+		return nil, fmt.Errorf("No objects found in %s", path)
+	}
+	return objects, nil
+}
+
+func isManifestFile(name string) bool {
+	for _, ext := range manifestExtensions {
+		if strings.EqualFold(filepath.Ext(name), ext) {
+			return true
+		}
+	}
+	return false
+}
+
+func readManifestFile(file string) ([]*unstructured.Unstructured, error) {
+	f, err := os.Open(file)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	objects := make([]*unstructured.Unstructured, 0)
+	reader := utilyaml.NewYAMLReader(bufio.NewReader(f))
+	for {
+		doc, err := reader.Read()
+		if err == io.EOF {
+			return objects, nil
+		} else if err != nil {
+			return nil, err
+		}
+		data, err := yaml.YAMLToJSON(doc)
+		if err != nil {
+			return nil, err
+		}
+		// documents holding nothing but comments
+		if strings.TrimSpace(string(data)) == "null" {
+			continue
+		}
+		obj := &unstructured.Unstructured{}
+		if err := obj.UnmarshalJSON(data); err != nil {
+			return nil, err
+		}
+		if !obj.IsList() {
+			objects = append(objects, obj)
+			continue
+		}
+		err = obj.EachListItem(func(item runtime.Object) error {
+			objects = append(objects, item.(*unstructured.Unstructured))
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+}
+
+// ResolveResource finds the resource type that serves a kind, so that
+// objects read from manifests can be sent through the dynamic client
+func (k *kubeFactory) ResolveResource(apiVersion, kind string) (*APIResource, error) {
+	gv, err := schema.ParseGroupVersion(apiVersion)
+	if err != nil {
+		return nil, err
+	}
+	list, err := k.clientset.Discovery().ServerResourcesForGroupVersion(apiVersion)
+	if err != nil {
+		return nil, err
+	}
+	for _, res := range list.APIResources {
+		if res.Kind == kind && !strings.Contains(res.Name, "/") {
+			return &APIResource{
+				Group:      gv.Group,
+				Version:    gv.Version,
+				Resource:   res.Name,
+				Kind:       res.Kind,
+				Namespaced: res.Namespaced,
+			}, nil
+		}
+	}
+	return nil, fmt.Errorf("The server doesn't serve %s in %s", kind, apiVersion)
+}
+
+// ApplyObject creates an object, or patches the one that exists the way
+// kubectl apply does. The manifest is kept in the last-applied annotation,
+// and the patch is worked out from it, the manifest and the live object:
+// fields the manifest sets are set, fields removed from it since it was
+// last applied are removed, and fields set by anything else are kept.
+// Lists are replaced whole. The existing object is returned with the
+// result, and is nil when the object was created. With dryRun the server
+// does everything but store the result.
+func (k *kubeFactory) ApplyObject(resource APIResource, obj *unstructured.Unstructured, dryRun bool) (before, after *unstructured.Unstructured, err error) {
+	var client dynamic.ResourceInterface = k.dynamic.Resource(resource.GroupVersionResource())
+	if resource.Namespaced {
+		client = k.dynamic.Resource(resource.GroupVersionResource()).Namespace(obj.GetNamespace())
+	}
+	var opts []string
+	if dryRun {
+		opts = []string{v1.DryRunAll}
+	}
+
+	modified, err := withLastApplied(obj)
+	if err != nil {
+		return
+	}
+	before, err = client.Get(obj.GetName(), v1.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		after, err = client.Create(modified, v1.CreateOptions{DryRun: opts})
+		return nil, after, err
+	} else if err != nil {
+		return
+	}
+
+	original := make(map[string]interface{})
+	if last := before.GetAnnotations()[corev1.LastAppliedConfigAnnotation]; last != "" {
+		if err = json.Unmarshal([]byte(last), &original); err != nil {
+			return nil, nil, fmt.Errorf("Couldn't read the last applied configuration of %s: %v", obj.GetName(), err)
+		}
+	}
+	patch := threeWayMergePatch(original, modified.Object, before.Object)
+	data, err := json.Marshal(patch)
+	if err != nil {
+		return
+	}
+	after, err = client.Patch(obj.GetName(), types.MergePatchType, data, v1.PatchOptions{DryRun: opts})
+	return
+}
+
+// withLastApplied returns a copy of an object read from a manifest with
+// the manifest recorded in the last-applied annotation, the same as
+// kubectl apply records it
+func withLastApplied(obj *unstructured.Unstructured) (*unstructured.Unstructured, error) {
+	modified := obj.DeepCopy()
+	unstructured.RemoveNestedField(modified.Object, "metadata", "resourceVersion")
+	annotations := modified.GetAnnotations()
+	delete(annotations, corev1.LastAppliedConfigAnnotation)
+	if len(annotations) == 0 {
+		annotations = nil
+	}
+	modified.SetAnnotations(annotations)
+	last, err := modified.MarshalJSON()
+	if err != nil {
+		return nil, err
+	}
+	if annotations == nil {
+		annotations = make(map[string]string)
+	}
+	annotations[corev1.LastAppliedConfigAnnotation] = strings.TrimSpace(string(last))
+	modified.SetAnnotations(annotations)
+	return modified, nil
+}
+
+// threeWayMergePatch works out a JSON merge patch that takes current to
+// modified, leaving alone the fields modified doesn't mention unless they
+// were in original, the last applied manifest, and so have been removed
+// from it since
+func threeWayMergePatch(original, modified, current map[string]interface{}) map[string]interface{} {
+	patch := make(map[string]interface{})
+	for key, value := range modified {
+		live, ok := current[key]
+		if ok && reflect.DeepEqual(live, value) {
+			continue
+		}
+		valueMap, isMap := value.(map[string]interface{})
+		liveMap, liveIsMap := live.(map[string]interface{})
+		if !isMap || !liveIsMap {
+			patch[key] = value
+			continue
+		}
+		originalMap, _ := original[key].(map[string]interface{})
+		if sub := threeWayMergePatch(originalMap, valueMap, liveMap); len(sub) > 0 {
+			patch[key] = sub
+		}
+	}
+	for key := range original {
+		if _, kept := modified[key]; kept {
+			continue
+		}
+		if _, live := current[key]; live {
+			patch[key] = nil
+		}
+	}
+	return patch
+}
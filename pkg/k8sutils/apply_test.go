@@ -0,0 +1,99 @@
+package k8sutils
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func decodeJSON(t *testing.T, doc string) map[string]interface{} {
+	out := make(map[string]interface{})
+	if err := json.Unmarshal([]byte(doc), &out); err != nil {
+		t.Fatal(err)
+	}
+	return out
+}
+
+func TestThreeWayMergePatch(t *testing.T) {
+	for _, tc := range []struct {
+		name                        string
+		original, modified, current string
+		patch                       string
+	}{
+		{
+			name:     "unchanged",
+			original: `{"spec":{"replicas":1}}`,
+			modified: `{"spec":{"replicas":1}}`,
+			current:  `{"spec":{"replicas":1},"status":{"ready":1}}`,
+			patch:    `{}`,
+		},
+		{
+			name:     "changed field",
+			original: `{"spec":{"replicas":1}}`,
+			modified: `{"spec":{"replicas":3}}`,
+			current:  `{"spec":{"replicas":1,"paused":false}}`,
+			patch:    `{"spec":{"replicas":3}}`,
+		},
+		{
+			name:     "field removed from the manifest",
+			original: `{"metadata":{"labels":{"a":"1","b":"2"}}}`,
+			modified: `{"metadata":{"labels":{"a":"1"}}}`,
+			current:  `{"metadata":{"labels":{"a":"1","b":"2","other":"3"}}}`,
+			patch:    `{"metadata":{"labels":{"b":null}}}`,
+		},
+		{
+			name:     "field set by someone else",
+			original: `{}`,
+			modified: `{"data":{"a":"1"}}`,
+			current:  `{"data":{"a":"1","b":"2"}}`,
+			patch:    `{}`,
+		},
+		{
+			name:     "removed field already gone",
+			original: `{"data":{"a":"1"}}`,
+			modified: `{}`,
+			current:  `{}`,
+			patch:    `{}`,
+		},
+		{
+			name:     "lists replaced whole",
+			original: `{"spec":{"ports":[{"port":80}]}}`,
+			modified: `{"spec":{"ports":[{"port":8080}]}}`,
+			current:  `{"spec":{"ports":[{"port":80,"protocol":"TCP"}]}}`,
+			patch:    `{"spec":{"ports":[{"port":8080}]}}`,
+		},
+	} {
+		patch := threeWayMergePatch(decodeJSON(t, tc.original), decodeJSON(t, tc.modified), decodeJSON(t, tc.current))
+		if expected := decodeJSON(t, tc.patch); !reflect.DeepEqual(patch, expected) {
+			t.Errorf("%s: expected patch %v, got %v", tc.name, expected, patch)
+		}
+	}
+}
+
+func TestWithLastApplied(t *testing.T) {
+	obj := &unstructured.Unstructured{Object: decodeJSON(t, `{
+		"apiVersion": "v1",
+		"kind": "ConfigMap",
+		"metadata": {
+			"name": "config",
+			"resourceVersion": "12",
+			"annotations": {"`+corev1.LastAppliedConfigAnnotation+`": "stale"}
+		},
+		"data": {"a": "1"}
+	}`)}
+	modified, err := withLastApplied(obj)
+	if err != nil {
+		t.Fatal(err)
+	}
+	last := decodeJSON(t, modified.GetAnnotations()[corev1.LastAppliedConfigAnnotation])
+	expected := decodeJSON(t, `{"apiVersion":"v1","kind":"ConfigMap","metadata":{"name":"config"},"data":{"a":"1"}}`)
+	if !reflect.DeepEqual(last, expected) {
+		t.Errorf("expected the last applied annotation to hold %v, got %v", expected, last)
+	}
+	if obj.GetAnnotations()[corev1.LastAppliedConfigAnnotation] != "stale" {
+		t.Errorf("expected the object read from the manifest to be left alone")
+	}
+}
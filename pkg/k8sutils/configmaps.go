@@ -0,0 +1,51 @@
+package k8sutils
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// ConfigMapSummary holds the columns shown by `kubectl get configmaps`
+type ConfigMapSummary struct {
+	Name    string
+	Keys    int
+	Created time.Time
+}
+
+// ListConfigMaps returns the configmaps in a namespace, sorted by name
+func (k *kubeFactory) ListConfigMaps(ns string) (configMaps []ConfigMapSummary, err error) {
+	res, err := k.clientset.CoreV1().ConfigMaps(ns).List(v1.ListOptions{})
+	if err != nil {
+		return
+	}
+	for _, cm := range res.Items {
+		configMaps = append(configMaps, ConfigMapSummary{
+			Name:    cm.Name,
+			Keys:    len(cm.Data) + len(cm.BinaryData),
+			Created: cm.CreationTimestamp.Time,
+		})
+	}
+	sort.Slice(configMaps, func(i, j int) bool { return configMaps[i].Name < configMaps[j].Name })
+	return
+}
+
+// GetConfigMap fetches a configmap
+func (k *kubeFactory) GetConfigMap(ns, name string) (*corev1.ConfigMap, error) {
+	return k.clientset.CoreV1().ConfigMaps(ns).Get(name, v1.GetOptions{})
+}
+
+// UpdateConfigMap replaces a configmap. The resourceVersion of the
+// configmap is sent along with it, so the update is refused if the
+// configmap has changed since it was read.
+func (k *kubeFactory) UpdateConfigMap(cm *corev1.ConfigMap) (*corev1.ConfigMap, error) {
+	updated, err := k.clientset.CoreV1().ConfigMaps(cm.Namespace).Update(cm)
+	if apierrors.IsConflict(err) {
+		return nil, fmt.Errorf("ConfigMap %s was changed by someone else after it was loaded, reload it and make your edit again", cm.Name)
+	}
+	return updated, err
+}
@@ -0,0 +1,299 @@
+package k8sutils
+
+import (
+	"archive/tar"
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+
+	"k8s.io/client-go/tools/remotecommand"
+)
+
+// CopyProgress is called as a copy moves data, with the bytes moved so far
+// and the total, or -1 when the total isn't known up front
+type CopyProgress func(done, total int64)
+
+// CopyToPod uploads a local file or directory into a directory in a
+// container, the way kubectl cp does, by piping a tar archive into tar
+// running in the container. The container needs a tar binary.
+func (k *kubeFactory) CopyToPod(ns, pod, container, src, destDir string, progress CopyProgress, ctx context.Context) error {
+	src = filepath.Clean(src)
+	total, err := localSize(src)
+	if err != nil {
+		return err
+	}
+	exec, err := k.GetExecutor(ns, pod, container, []string{"tar", "-xmf", "-", "-C", destDir}, false)
+	if err != nil {
+		return err
+	}
+
+	reader, writer := io.Pipe()
+	go func() {
+		<-ctx.Done()
+		writer.CloseWithError(ctx.Err())
+	}()
+	go func() {
+		counter := &countingWriter{Writer: writer, total: total, progress: progress}
+		writer.CloseWithError(writeTar(counter, src))
+	}()
+
+	var stderr bytes.Buffer
+	err = exec.Stream(remotecommand.StreamOptions{
+		Stdin:  reader,
+		Stdout: &stderr,
+		Stderr: &stderr,
+	})
+	reader.Close()
+	return execError(err, &stderr, ctx)
+}
+
+// CopyFromPod downloads a file or directory from a container into a local
+// directory, by reading a tar archive from tar running in the container.
+// The archive comes from a container that can't be trusted, so entries
+// that would land outside of destDir are refused, and like kubectl cp,
+// symlinks and hard links aren't extracted but skipped and returned.
+func (k *kubeFactory) CopyFromPod(ns, pod, container, src, destDir string, progress CopyProgress, ctx context.Context) (skipped []string, err error) {
+	src = path.Clean(src)
+	exec, err := k.GetExecutor(ns, pod, container, []string{"tar", "cf", "-", "-C", path.Dir(src), path.Base(src)}, false)
+	if err != nil {
+		return nil, err
+	}
+
+	reader, writer := io.Pipe()
+	go func() {
+		<-ctx.Done()
+		reader.CloseWithError(ctx.Err())
+	}()
+	var stderr bytes.Buffer
+	streamErr := make(chan error, 1)
+	go func() {
+		err := exec.Stream(remotecommand.StreamOptions{
+			Stdin:  bytes.NewReader(nil),
+			Stdout: &drainingWriter{Writer: &countingWriter{Writer: writer, total: -1, progress: progress}},
+			Stderr: &stderr,
+		})
+		writer.CloseWithError(err)
+		streamErr <- err
+	}()
+
+	skipped, err = readTar(reader, destDir)
+	// when extracting stopped early, the rest of the archive is thrown
+	// away in the background until the remote tar is done with it
+	reader.Close()
+	if ctx.Err() != nil {
+		return skipped, ctx.Err()
+	}
+	if err != nil {
+		return skipped, err
+	}
+	return skipped, execError(<-streamErr, &stderr, ctx)
+}
+
+// execError adds what a command wrote to stderr to the error it failed
+// with
+func execError(err error, stderr *bytes.Buffer, ctx context.Context) error {
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
+	if err == nil {
+		return nil
+	}
+	if msg := strings.TrimSpace(stderr.String()); msg != "" {
+		return fmt.Errorf("%v: %s", err, msg)
+	}
+	return err
+}
+
+func localSize(src string) (size int64, err error) {
+	err = filepath.Walk(src, func(_ string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.Mode().IsRegular() {
+			size += info.Size()
+		}
+		return nil
+	})
+	return
+}
+
+// writeTar archives a file or directory under its base name
+func writeTar(w io.Writer, src string) error {
+	tw := tar.NewWriter(w)
+	base := filepath.Dir(src)
+	err := filepath.Walk(src, func(file string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		name, err := filepath.Rel(base, file)
+		if err != nil {
+			return err
+		}
+		var link string
+		if info.Mode()&os.ModeSymlink != 0 {
+			if link, err = os.Readlink(file); err != nil {
+				return err
+			}
+		}
+		hdr, err := tar.FileInfoHeader(info, link)
+		if err != nil {
+			return err
+		}
+		hdr.Name = filepath.ToSlash(name)
+		if err = tw.WriteHeader(hdr); err != nil {
+			return err
+		}
+		if !info.Mode().IsRegular() {
+			return nil
+		}
+		f, err := os.Open(file)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		_, err = io.Copy(tw, f)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+	return tw.Close()
+}
+
+// readTar extracts an archive into destDir, refusing any entry that would
+// be written outside of it. Links are skipped, an archive could otherwise
+// create one and write through it, and nothing is written through links
+// already in destDir.
+func readTar(r io.Reader, destDir string) (skipped []string, err error) {
+	if err = os.MkdirAll(destDir, 0755); err != nil {
+		return
+	}
+	if destDir, err = filepath.Abs(destDir); err != nil {
+		return
+	}
+	if destDir, err = filepath.EvalSymlinks(destDir); err != nil {
+		return
+	}
+	tr := tar.NewReader(r)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			return skipped, nil
+		} else if err != nil {
+			return skipped, err
+		}
+		target := filepath.Join(destDir, filepath.FromSlash(hdr.Name))
+		if !within(destDir, target) {
+			return skipped, fmt.Errorf("Refusing to extract %s outside of %s", hdr.Name, destDir)
+		}
+
+		switch hdr.Typeflag {
+		case tar.TypeDir:
+			if err := mkdirWithin(destDir, target, os.FileMode(hdr.Mode)|0700); err != nil {
+				return skipped, err
+			}
+
+		case tar.TypeReg, tar.TypeRegA:
+			if err := mkdirWithin(destDir, filepath.Dir(target), 0755); err != nil {
+				return skipped, err
+			}
+			if info, err := os.Lstat(target); err == nil && !info.Mode().IsRegular() {
+				return skipped, fmt.Errorf("Refusing to extract %s over %s, which is not a regular file", hdr.Name, target)
+			}
+			f, err := os.OpenFile(target, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, os.FileMode(hdr.Mode))
+			if err != nil {
+				return skipped, err
+			}
+			_, err = io.Copy(f, tr)
+			f.Close()
+			if err != nil {
+				return skipped, err
+			}
+
+		case tar.TypeSymlink:
+			skipped = append(skipped, fmt.Sprintf("%s -> %s", hdr.Name, hdr.Linkname))
+
+		case tar.TypeLink:
+			skipped = append(skipped, fmt.Sprintf("%s => %s", hdr.Name, hdr.Linkname))
+
+		default:
+			// devices, fifos and the like have no business in a copy
+			skipped = append(skipped, hdr.Name)
+		}
+	}
+}
+
+// mkdirWithin creates dir and its parents below root one at a time,
+// refusing to go through anything that isn't a real directory
+func mkdirWithin(root, dir string, mode os.FileMode) error {
+	rel, err := filepath.Rel(root, dir)
+	if err != nil {
+		return err
+	}
+	current := root
+	for _, part := range strings.Split(rel, string(filepath.Separator)) {
+		if part == "." || part == "" {
+			continue
+		}
+		current = filepath.Join(current, part)
+		info, err := os.Lstat(current)
+		if os.IsNotExist(err) {
+			if err = os.Mkdir(current, mode); err != nil {
+				return err
+			}
+			continue
+		} else if err != nil {
+			return err
+		}
+		if !info.IsDir() {
+			return fmt.Errorf("Refusing to extract into %s, which is not a directory", current)
+		}
+	}
+	return nil
+}
+
+// within is true when path is dir or somewhere beneath it
+func within(dir, path string) bool {
+	rel, err := filepath.Rel(dir, filepath.Clean(path))
+	return err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
+
+// drainingWriter passes writes on until one fails, then throws the rest
+// away. A stream that is written to stalls when its output isn't read, so
+// this keeps it going to the end once nothing wants what it sends.
+type drainingWriter struct {
+	io.Writer
+	failed bool
+}
+
+func (w *drainingWriter) Write(p []byte) (int, error) {
+	if !w.failed {
+		if _, err := w.Writer.Write(p); err != nil {
+			w.failed = true
+		}
+	}
+	return len(p), nil
+}
+
+// countingWriter reports the bytes written through it
+type countingWriter struct {
+	io.Writer
+	done     int64
+	total    int64
+	progress CopyProgress
+}
+
+func (w *countingWriter) Write(p []byte) (int, error) {
+	n, err := w.Writer.Write(p)
+	done := atomic.AddInt64(&w.done, int64(n))
+	if w.progress != nil {
+		w.progress(done, w.total)
+	}
+	return n, err
+}
@@ -0,0 +1,190 @@
+package k8sutils
+
+import (
+	"archive/tar"
+	"bytes"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+type tarEntry struct {
+	name     string
+	typeflag byte
+	linkname string
+	body     string
+}
+
+func buildTar(t *testing.T, entries []tarEntry) *bytes.Buffer {
+	var buf bytes.Buffer
+	tw := tar.NewWriter(&buf)
+	for _, e := range entries {
+		hdr := &tar.Header{
+			Name:     e.name,
+			Typeflag: e.typeflag,
+			Linkname: e.linkname,
+			Mode:     0644,
+			Size:     int64(len(e.body)),
+		}
+		if err := tw.WriteHeader(hdr); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := tw.Write([]byte(e.body)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := tw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return &buf
+}
+
+// tempDest returns an empty destination directory inside a parent that
+// tests check nothing escapes into
+func tempDest(t *testing.T) (parent, dest string) {
+	parent, err := ioutil.TempDir("", "readtar")
+	if err != nil {
+		t.Fatal(err)
+	}
+	dest = filepath.Join(parent, "dest")
+	if err := os.Mkdir(dest, 0755); err != nil {
+		t.Fatal(err)
+	}
+	return parent, dest
+}
+
+func assertMissing(t *testing.T, path string) {
+	if _, err := os.Lstat(path); !os.IsNotExist(err) {
+		t.Errorf("%s was written outside of the destination", path)
+	}
+}
+
+func TestReadTarSymlinkChain(t *testing.T) {
+	parent, dest := tempDest(t)
+	defer os.RemoveAll(parent)
+
+	skipped, err := readTar(buildTar(t, []tarEntry{
+		{name: "b", typeflag: tar.TypeSymlink, linkname: "."},
+		{name: "a", typeflag: tar.TypeSymlink, linkname: "b/.."},
+		{name: "a/evil", typeflag: tar.TypeReg, body: "evil"},
+	}), dest)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(skipped) != 2 {
+		t.Errorf("expected both symlinks to be skipped, got %v", skipped)
+	}
+	assertMissing(t, filepath.Join(parent, "evil"))
+	if info, err := os.Lstat(filepath.Join(dest, "a")); err != nil || !info.IsDir() {
+		t.Errorf("expected a to be extracted as a plain directory")
+	}
+}
+
+func TestReadTarHardLink(t *testing.T) {
+	parent, dest := tempDest(t)
+	defer os.RemoveAll(parent)
+	outside := filepath.Join(parent, "secret")
+	if err := ioutil.WriteFile(outside, []byte("secret"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	skipped, err := readTar(buildTar(t, []tarEntry{
+		{name: "link", typeflag: tar.TypeLink, linkname: "../secret"},
+		{name: "link", typeflag: tar.TypeReg, body: "overwritten"},
+	}), dest)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(skipped) != 1 {
+		t.Errorf("expected the hard link to be skipped, got %v", skipped)
+	}
+	if body, _ := ioutil.ReadFile(outside); string(body) != "secret" {
+		t.Errorf("file outside of the destination was changed to %q", body)
+	}
+}
+
+func TestReadTarExistingSymlinks(t *testing.T) {
+	parent, dest := tempDest(t)
+	defer os.RemoveAll(parent)
+	outsideDir := filepath.Join(parent, "outside")
+	if err := os.Mkdir(outsideDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	outsideFile := filepath.Join(parent, "file")
+	if err := ioutil.WriteFile(outsideFile, []byte("original"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Symlink(outsideDir, filepath.Join(dest, "dir")); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Symlink(outsideFile, filepath.Join(dest, "file")); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := readTar(buildTar(t, []tarEntry{
+		{name: "dir/evil", typeflag: tar.TypeReg, body: "evil"},
+	}), dest); err == nil {
+		t.Errorf("expected writing through a symlinked directory to be refused")
+	}
+	assertMissing(t, filepath.Join(outsideDir, "evil"))
+
+	if _, err := readTar(buildTar(t, []tarEntry{
+		{name: "file", typeflag: tar.TypeReg, body: "evil"},
+	}), dest); err == nil {
+		t.Errorf("expected writing through a symlinked file to be refused")
+	}
+	if body, _ := ioutil.ReadFile(outsideFile); string(body) != "original" {
+		t.Errorf("file outside of the destination was changed to %q", body)
+	}
+}
+
+func TestReadTarTraversal(t *testing.T) {
+	parent, dest := tempDest(t)
+	defer os.RemoveAll(parent)
+
+	if _, err := readTar(buildTar(t, []tarEntry{
+		{name: "../evil", typeflag: tar.TypeReg, body: "evil"},
+	}), dest); err == nil {
+		t.Errorf("expected an entry outside of the destination to be refused")
+	}
+	assertMissing(t, filepath.Join(parent, "evil"))
+}
+
+func TestReadTarFiles(t *testing.T) {
+	parent, dest := tempDest(t)
+	defer os.RemoveAll(parent)
+
+	skipped, err := readTar(buildTar(t, []tarEntry{
+		{name: "top", typeflag: tar.TypeDir},
+		{name: "top/sub/file", typeflag: tar.TypeReg, body: "hello"},
+	}), dest)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(skipped) != 0 {
+		t.Errorf("expected nothing to be skipped, got %v", skipped)
+	}
+	if body, err := ioutil.ReadFile(filepath.Join(dest, "top", "sub", "file")); err != nil || string(body) != "hello" {
+		t.Errorf("expected top/sub/file to hold hello, got %q, %v", body, err)
+	}
+}
+
+func TestDrainingWriter(t *testing.T) {
+	reader, writer := io.Pipe()
+	w := &drainingWriter{Writer: writer}
+	go func() {
+		buf := make([]byte, 2)
+		io.ReadFull(reader, buf)
+		reader.Close()
+	}()
+	for i := 0; i < 3; i++ {
+		if n, err := w.Write([]byte("ab")); n != 2 || err != nil {
+			t.Errorf("expected writes to keep succeeding once nothing reads them, got %d, %v", n, err)
+		}
+	}
+	if !w.failed {
+		t.Errorf("expected the writer to be draining once its reader was closed")
+	}
+}
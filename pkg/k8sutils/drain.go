@@ -0,0 +1,196 @@
+package k8sutils
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+const (
+	drainRetryInterval = time.Duration(5) * time.Second
+	drainPollInterval  = time.Duration(1) * time.Second
+)
+
+// Statuses reported for each pod during a drain
+const (
+	DrainSkippedDaemonSet = "Skipped, managed by a DaemonSet"
+	DrainSkippedMirror    = "Skipped, mirror pod"
+	DrainEvicting         = "Evicting"
+	DrainBlocked          = "Blocked by a PodDisruptionBudget, retrying"
+	DrainTerminating      = "Terminating"
+	DrainEvicted          = "Evicted"
+	DrainFailed           = "Failed"
+)
+
+// DrainOptions controls how DrainNode treats the pods on a node
+type DrainOptions struct {
+	// DeleteEmptyDirData allows evicting pods with emptyDir volumes,
+	// whose data is lost when the pod goes
+	DeleteEmptyDirData bool
+	// Force allows evicting pods that no controller manages, which
+	// nothing recreates once they are gone
+	Force bool
+	// Timeout is how long the whole drain may take before the pods left
+	// are given up on
+	Timeout time.Duration
+}
+
+// DrainProgress reports a change in status of one of the pods on a node
+// being drained
+type DrainProgress struct {
+	Namespace string
+	Pod       string
+	Status    string
+	Err       error
+}
+
+// Done is true once nothing more will happen to the pod
+func (d DrainProgress) Done() bool {
+	switch d.Status {
+	case DrainEvicting, DrainBlocked, DrainTerminating:
+		return false
+	}
+	return true
+}
+
+// CordonNode marks a node unschedulable, or schedulable again
+func (k *kubeFactory) CordonNode(name string, unschedulable bool) error {
+	patch := []byte(fmt.Sprintf(`{"spec":{"unschedulable":%t}}`, unschedulable))
+	_, err := k.clientset.CoreV1().Nodes().Patch(name, types.StrategicMergePatchType, patch)
+	return err
+}
+
+// DrainNode cordons a node and evicts every pod on it that isn't managed by
+// a DaemonSet or mirrored from a static manifest, the same as
+// `kubectl drain --ignore-daemonsets`. Like kubectl, it refuses to drain a
+// node with pods that no controller manages unless forced. Pods are
+// evicted in parallel, and evictions refused by a PodDisruptionBudget are
+// retried until the timeout. Progress is sent for every pod and the channel is closed once
+// every pod has gone or failed.
+func (k *kubeFactory) DrainNode(name string, opts DrainOptions, ctx context.Context) (<-chan DrainProgress, error) {
+	pods, err := k.listActivePods(fields.OneTermEqualSelector("spec.nodeName", name))
+	if err != nil {
+		return nil, err
+	}
+	evict := make([]corev1.Pod, 0)
+	skipped := make([]DrainProgress, 0)
+	withEmptyDir := make([]string, 0)
+	unmanaged := make([]string, 0)
+	for _, pod := range pods {
+		if _, ok := pod.Annotations[corev1.MirrorPodAnnotationKey]; ok {
+			skipped = append(skipped, DrainProgress{Namespace: pod.Namespace, Pod: pod.Name, Status: DrainSkippedMirror})
+			continue
+		}
+		owner := v1.GetControllerOf(&pod)
+		if owner != nil && owner.Kind == KindDaemonSet {
+			skipped = append(skipped, DrainProgress{Namespace: pod.Namespace, Pod: pod.Name, Status: DrainSkippedDaemonSet})
+			continue
+		}
+		if owner == nil && !opts.Force {
+			unmanaged = append(unmanaged, fmt.Sprintf("%s/%s", pod.Namespace, pod.Name))
+		}
+		if !opts.DeleteEmptyDirData && hasEmptyDir(&pod) {
+			withEmptyDir = append(withEmptyDir, fmt.Sprintf("%s/%s", pod.Namespace, pod.Name))
+		}
+		evict = append(evict, pod)
+	}
+	if len(unmanaged) > 0 {
+		return nil, fmt.Errorf("Refusing to drain %s, these pods aren't managed by a controller and wouldn't be recreated: %s",
+			name, strings.Join(unmanaged, ", "))
+	}
+	if len(withEmptyDir) > 0 {
+		return nil, fmt.Errorf("Refusing to drain %s, these pods use emptyDir volumes whose data would be deleted: %s",
+			name, strings.Join(withEmptyDir, ", "))
+	}
+
+	if err := k.CordonNode(name, true); err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, opts.Timeout)
+	progress := make(chan DrainProgress)
+	var wg sync.WaitGroup
+	for idx := range evict {
+		wg.Add(1)
+		go func(pod *corev1.Pod) {
+			defer wg.Done()
+			k.drainPod(ctx, pod, progress)
+		}(&evict[idx])
+	}
+	go func() {
+		for _, skip := range skipped {
+			progress <- skip
+		}
+		wg.Wait()
+		cancel()
+		close(progress)
+	}()
+	return progress, nil
+}
+
+// drainPod evicts a single pod and waits for it to go away
+func (k *kubeFactory) drainPod(ctx context.Context, pod *corev1.Pod, progress chan<- DrainProgress) {
+	report := func(status string, err error) {
+		progress <- DrainProgress{Namespace: pod.Namespace, Pod: pod.Name, Status: status, Err: err}
+	}
+	timedOut := func(waiting string) {
+		report(DrainFailed, fmt.Errorf("Gave up waiting for %s: %v", waiting, ctx.Err()))
+	}
+
+	report(DrainEvicting, nil)
+	for {
+		err := k.evictPod(pod.Namespace, pod.Name)
+		if err == nil || apierrors.IsNotFound(err) {
+			break
+		}
+		if !apierrors.IsTooManyRequests(err) {
+			report(DrainFailed, err)
+			return
+		}
+		report(DrainBlocked, err)
+		select {
+		case <-ctx.Done():
+			timedOut("the PodDisruptionBudget to allow eviction")
+			return
+		case <-time.After(drainRetryInterval):
+		}
+	}
+
+	report(DrainTerminating, nil)
+	for {
+		current, err := k.clientset.CoreV1().Pods(pod.Namespace).Get(pod.Name, v1.GetOptions{})
+		// a pod with the same name but a new UID is a replacement from a
+		// StatefulSet, so the one we evicted is gone
+		if apierrors.IsNotFound(err) || (err == nil && current.UID != pod.UID) {
+			report(DrainEvicted, nil)
+			return
+		}
+		if err != nil {
+			report(DrainFailed, err)
+			return
+		}
+		select {
+		case <-ctx.Done():
+			timedOut("the pod to terminate")
+			return
+		case <-time.After(drainPollInterval):
+		}
+	}
+}
+
+func hasEmptyDir(pod *corev1.Pod) bool {
+	for _, volume := range pod.Spec.Volumes {
+		if volume.EmptyDir != nil {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,113 @@
+package k8sutils
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/client-go/tools/cache"
+)
+
+// EventSummary holds the columns shown by `kubectl get events`
+type EventSummary struct {
+	Type      string
+	Reason    string
+	Object    string
+	Source    string
+	Message   string
+	Count     int32
+	FirstSeen time.Time
+	LastSeen  time.Time
+}
+
+// Warning is true for events of type Warning
+func (e EventSummary) Warning() bool {
+	return e.Type == corev1.EventTypeWarning
+}
+
+// ListEvents returns the events in a namespace, oldest first. When kind and
+// name are given only the events about that object are returned.
+func (k *kubeFactory) ListEvents(ns, kind, name string) (events []EventSummary, err error) {
+	selector := fields.Everything()
+	if kind != "" && name != "" {
+		selector = fields.SelectorFromSet(fields.Set{
+			"involvedObject.kind": kind,
+			"involvedObject.name": name,
+		})
+	}
+	res, err := k.clientset.CoreV1().Events(ns).List(v1.ListOptions{FieldSelector: selector.String()})
+	if err != nil {
+		return
+	}
+	for idx := range res.Items {
+		events = append(events, newEventSummary(&res.Items[idx]))
+	}
+	sortEvents(events)
+	return
+}
+
+// WatchEvents streams the events in a namespace, oldest first, sending the
+// full list every time an event is recorded or updated. It is backed by an
+// informer the same as WatchPods.
+func (k *kubeFactory) WatchEvents(ns string, ctx context.Context) (<-chan []EventSummary, error) {
+	if _, err := k.clientset.CoreV1().Events(ns).List(v1.ListOptions{Limit: 1}); err != nil {
+		return nil, err
+	}
+	lw := cache.NewListWatchFromClient(k.clientset.CoreV1().RESTClient(), "events", ns, fields.Everything())
+	snapshots := watchResource(ctx, lw, &corev1.Event{})
+	events := make(chan []EventSummary)
+	go func() {
+		defer close(events)
+		for objs := range snapshots {
+			summaries := make([]EventSummary, 0)
+			for _, obj := range objs {
+				summaries = append(summaries, newEventSummary(obj.(*corev1.Event)))
+			}
+			sortEvents(summaries)
+			select {
+			case events <- summaries:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return events, nil
+}
+
+func sortEvents(events []EventSummary) {
+	sort.SliceStable(events, func(i, j int) bool { return events[i].LastSeen.Before(events[j].LastSeen) })
+}
+
+func newEventSummary(ev *corev1.Event) EventSummary {
+	summary := EventSummary{
+		Type:      ev.Type,
+		Reason:    ev.Reason,
+		Object:    fmt.Sprintf("%s/%s", ev.InvolvedObject.Kind, ev.InvolvedObject.Name),
+		Source:    ev.Source.Component,
+		Message:   ev.Message,
+		Count:     ev.Count,
+		FirstSeen: ev.FirstTimestamp.Time,
+		LastSeen:  ev.LastTimestamp.Time,
+	}
+	if ev.Source.Host != "" {
+		summary.Source = fmt.Sprintf("%s, %s", ev.Source.Component, ev.Source.Host)
+	}
+	// events recorded through the events.k8s.io API only set EventTime
+	if summary.LastSeen.IsZero() {
+		summary.LastSeen = ev.EventTime.Time
+	}
+	if summary.LastSeen.IsZero() {
+		summary.LastSeen = ev.CreationTimestamp.Time
+	}
+	if summary.FirstSeen.IsZero() {
+		summary.FirstSeen = summary.LastSeen
+	}
+	if summary.Count == 0 {
+		summary.Count = 1
+	}
+	return summary
+}
@@ -1,13 +1,24 @@
 package k8sutils
 
 import (
+	"bytes"
+	"errors"
 	"io"
+	"strings"
 
 	v1 "k8s.io/api/core/v1"
 	"k8s.io/client-go/kubernetes/scheme"
 	"k8s.io/client-go/tools/remotecommand"
+	utilexec "k8s.io/client-go/util/exec"
 )
 
+// Shells are the shells looked for in a container, best first
+var Shells = []string{"bash", "ash", "sh"}
+
+// ErrNoShell is what DetectShell returns for images without any of the
+// Shells, like distroless ones
+var ErrNoShell = errors.New("No shell found in the container")
+
 type AttachOptions struct {
 	Stdin     io.Reader
 	Stdout    io.Writer
@@ -15,7 +26,10 @@ type AttachOptions struct {
 	Namespace string
 }
 
-func (k *kubeFactory) GetExecutor(ns, pod, container string) (exec remotecommand.Executor, err error) {
+// GetExecutor prepares to run a command in a container with stdin, stdout
+// and stderr attached. Interactive shells want a tty, anything moving
+// binary data through the streams must not have one.
+func (k *kubeFactory) GetExecutor(ns, pod, container string, command []string, tty bool) (exec remotecommand.Executor, err error) {
 	req := k.clientset.CoreV1().RESTClient().Post().
 		Resource("pods").
 		Name(pod).
@@ -25,11 +39,11 @@ func (k *kubeFactory) GetExecutor(ns, pod, container string) (exec remotecommand
 
 	req.VersionedParams(&v1.PodExecOptions{
 		Container: container,
-		Command:   []string{"/bin/sh"},
+		Command:   command,
 		Stdin:     true,
 		Stdout:    true,
 		Stderr:    true,
-		TTY:       true,
+		TTY:       tty,
 	}, scheme.ParameterCodec)
 
 	exec, err = remotecommand.NewSPDYExecutor(k.conf, "POST", req.URL())
@@ -39,3 +53,39 @@ func (k *kubeFactory) GetExecutor(ns, pod, container string) (exec remotecommand
 
 	return
 }
+
+// DetectShell finds the best shell in a container by running each of
+// Shells in turn until one starts
+func (k *kubeFactory) DetectShell(ns, pod, container string) (string, error) {
+	for _, shell := range Shells {
+		exec, err := k.GetExecutor(ns, pod, container, []string{shell, "-c", "exit 0"}, false)
+		if err != nil {
+			return "", err
+		}
+		var out bytes.Buffer
+		err = exec.Stream(remotecommand.StreamOptions{
+			Stdin:  bytes.NewReader(nil),
+			Stdout: &out,
+			Stderr: &out,
+		})
+		if err == nil {
+			return shell, nil
+		}
+		if !commandNotFound(err) {
+			return "", err
+		}
+	}
+	return "", ErrNoShell
+}
+
+// commandNotFound is true for the errors a runtime fails an exec with when
+// the command isn't in the container. Runtimes either exit with 126 or 127,
+// like a shell does, or fail the stream saying the executable wasn't found.
+// Anything else, a shell exiting with another code or the API refusing
+// the exec, is a real error.
+func commandNotFound(err error) bool {
+	if exitErr, ok := err.(utilexec.ExitError); ok {
+		return exitErr.ExitStatus() == 126 || exitErr.ExitStatus() == 127
+	}
+	return strings.Contains(strings.ToLower(err.Error()), "executable file not found")
+}
@@ -0,0 +1,26 @@
+package k8sutils
+
+import (
+	"errors"
+	"testing"
+
+	utilexec "k8s.io/client-go/util/exec"
+)
+
+func TestCommandNotFound(t *testing.T) {
+	for _, tc := range []struct {
+		err      error
+		notFound bool
+	}{
+		{utilexec.CodeExitError{Err: errors.New("command terminated with exit code 127"), Code: 127}, true},
+		{utilexec.CodeExitError{Err: errors.New("command terminated with exit code 126"), Code: 126}, true},
+		{utilexec.CodeExitError{Err: errors.New("command terminated with exit code 1"), Code: 1}, false},
+		{errors.New(`OCI runtime exec failed: exec failed: container_linux.go:349: starting container process caused "exec: \"bash\": executable file not found in $PATH": unknown`), true},
+		{errors.New(`pods "x" not found`), false},
+		{errors.New("open /etc/passwd: no such file or directory"), false},
+	} {
+		if got := commandNotFound(tc.err); got != tc.notFound {
+			t.Errorf("commandNotFound(%q) = %v, expected %v", tc.err, got, tc.notFound)
+		}
+	}
+}
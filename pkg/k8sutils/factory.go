@@ -10,7 +10,10 @@ import (
 	"path/filepath"
 
 	"gopkg.in/yaml.v2"
+	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/client-go/dynamic"
 	"k8s.io/client-go/kubernetes"
 	v1 "k8s.io/client-go/kubernetes/typed/core/v1"
 	"k8s.io/client-go/rest"
@@ -18,11 +21,16 @@ import (
 	"k8s.io/client-go/tools/remotecommand"
 )
 
+// inClusterContext is the context name given to in-cluster config, which
+// doesn't have one
+const inClusterContext = "in-cluster"
+
 type KubernetesFactory interface {
 	BuildConfigFromFlags(string, string) (*rest.Config, error)
 	NewForConfig(*rest.Config) (*kubernetes.Clientset, error)
 
 	AvailableContexts() ([]string, error)
+	CurrentContext() string
 	SwitchContext(string) error
 	CreateClientSet() error
 
@@ -30,11 +38,50 @@ type KubernetesFactory interface {
 	APIVersion() (string, error)
 
 	ListNamespaces() ([]string, error)
-	ListPods(string) ([]string, error)
+	ListPods(string) ([]PodSummary, error)
+	WatchPods(string, PodFilter, context.Context) (<-chan []PodSummary, error)
+	ListWorkloads(string) ([]WorkloadSummary, error)
+	ListServices(string) ([]ServiceSummary, error)
+	ListEndpoints(string, string) ([]EndpointAddress, error)
+	ListIngressRoutes(string) ([]IngressRoute, error)
+	ListConfigMaps(string) ([]ConfigMapSummary, error)
+	ListSecrets(string) ([]SecretSummary, error)
+	ListEvents(string, string, string) ([]EventSummary, error)
+	WatchEvents(string, context.Context) (<-chan []EventSummary, error)
+	ListNodes() ([]NodeSummary, error)
+	ListAPIResources() ([]APIResource, error)
+	ListObjects(APIResource, string) ([]ObjectSummary, error)
 
 	GetPod(string, string) (*corev1.Pod, error)
+	GetWorkload(string, string, string) (*WorkloadSummary, error)
+	GetNode(string) (*NodeSummary, error)
+	GetConfigMap(string, string) (*corev1.ConfigMap, error)
+	GetSecret(string, string) (*corev1.Secret, error)
+	GetObject(APIResource, string, string) (*unstructured.Unstructured, error)
+	ResolveResource(string, string) (*APIResource, error)
+	GetDeployment(string, string) (*appsv1.Deployment, error)
+	GetStatefulSet(string, string) (*appsv1.StatefulSet, error)
+	GetDaemonSet(string, string) (*appsv1.DaemonSet, error)
+	GetReplicaSet(string, string) (*appsv1.ReplicaSet, error)
+	ListRevisions(string, string) ([]Revision, error)
+
+	DeletePod(string, string, *int64) error
+	EvictPod(string, string) error
+	ScaleWorkload(string, string, string, int32) error
+	RestartWorkload(string, string, string) error
+	RollbackDeployment(string, string, int64) error
+	UpdateConfigMap(*corev1.ConfigMap) (*corev1.ConfigMap, error)
+	UpdateObject(APIResource, *unstructured.Unstructured) (*unstructured.Unstructured, error)
+	ApplyObject(APIResource, *unstructured.Unstructured, bool) (*unstructured.Unstructured, *unstructured.Unstructured, error)
+	CordonNode(string, bool) error
+	DrainNode(string, DrainOptions, context.Context) (<-chan DrainProgress, error)
 	GetLogStream(string, string, string, context.Context) (io.ReadCloser, error)
-	GetExecutor(string, string, string) (remotecommand.Executor, error)
+	GetExecutor(string, string, string, []string, bool) (remotecommand.Executor, error)
+	DetectShell(string, string, string) (string, error)
+	CopyToPod(string, string, string, string, string, CopyProgress, context.Context) error
+	CopyFromPod(string, string, string, string, string, CopyProgress, context.Context) ([]string, error)
+	PortForward(string, string, []string) (*PortForward, error)
+	ResolveServicePod(string, string, []string, string) (string, []string, error)
 }
 
 type kubeContexts struct {
@@ -61,8 +108,10 @@ type kubeFactory struct {
 	namespacesFunc  func(CoreV1) v1.NamespaceInterface
 
 	incluster bool
+	context   string
 	conf      *rest.Config
 	clientset *kubernetes.Clientset
+	dynamic   dynamic.Interface
 }
 
 func New(incluster bool) KubernetesFactory {
@@ -91,6 +140,11 @@ func (k *kubeFactory) Namespaces(c CoreV1) (iface v1.NamespaceInterface) {
 	return k.namespacesFunc(c)
 }
 
+// CurrentContext returns the name of the kubeconfig context in use
+func (k *kubeFactory) CurrentContext() string {
+	return k.context
+}
+
 func (k *kubeFactory) APIHost() string {
 	return k.conf.Host
 }
@@ -107,13 +161,21 @@ func (k *kubeFactory) APIVersion() (version string, err error) {
 func (k *kubeFactory) CreateClientSet() (err error) {
 	if k.incluster {
 		k.conf, err = rest.InClusterConfig()
+		k.context = inClusterContext
 	} else {
 		k.conf, err = k.BuildConfigFromFlags("", getKubeConfig())
+		if raw, rawErr := clientcmd.LoadFromFile(getKubeConfig()); rawErr == nil {
+			k.context = raw.CurrentContext
+		}
 	}
 	if err != nil {
 		return
 	}
 	k.clientset, err = k.NewForConfig(k.conf)
+	if err != nil {
+		return
+	}
+	k.dynamic, err = dynamic.NewForConfig(k.conf)
 	return
 }
 
@@ -151,7 +213,12 @@ func (k *kubeFactory) SwitchContext(ctx string) (err error) {
 	if err != nil {
 		return
 	}
+	k.context = ctx
 	k.clientset, err = k.NewForConfig(k.conf)
+	if err != nil {
+		return
+	}
+	k.dynamic, err = dynamic.NewForConfig(k.conf)
 	return
 }
 
@@ -0,0 +1,167 @@
+package k8sutils
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	networkingv1beta1 "k8s.io/api/networking/v1beta1"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+)
+
+const (
+	anyHost        = "*"
+	anyPath        = "*"
+	defaultBackend = "(default backend)"
+)
+
+// IngressRoute is a single host and path of an ingress along with the
+// backend it routes to. Problem describes why traffic won't reach the
+// backend, if it can tell.
+type IngressRoute struct {
+	Ingress   string
+	Host      string
+	Path      string
+	Service   string
+	Port      string
+	TLSSecret string
+	Problem   string
+}
+
+// ListIngressRoutes flattens every ingress in a namespace into one route per
+// host and path, checking each backend against the services and endpoints
+// in the namespace
+func (k *kubeFactory) ListIngressRoutes(ns string) (routes []IngressRoute, err error) {
+	ingresses, err := k.clientset.NetworkingV1beta1().Ingresses(ns).List(v1.ListOptions{})
+	if err != nil {
+		return
+	}
+	services, err := k.clientset.CoreV1().Services(ns).List(v1.ListOptions{})
+	if err != nil {
+		return
+	}
+	endpoints, err := k.clientset.CoreV1().Endpoints(ns).List(v1.ListOptions{})
+	if err != nil {
+		return
+	}
+	servicesByName := make(map[string]*corev1.Service)
+	for idx := range services.Items {
+		servicesByName[services.Items[idx].Name] = &services.Items[idx]
+	}
+	endpointsByName := make(map[string]*corev1.Endpoints)
+	for idx := range endpoints.Items {
+		endpointsByName[endpoints.Items[idx].Name] = &endpoints.Items[idx]
+	}
+
+	routes = make([]IngressRoute, 0)
+	for _, ing := range ingresses.Items {
+		newRoute := func(host, path string, backend networkingv1beta1.IngressBackend) IngressRoute {
+			return IngressRoute{
+				Ingress:   ing.Name,
+				Host:      host,
+				Path:      path,
+				Service:   backend.ServiceName,
+				Port:      backend.ServicePort.String(),
+				TLSSecret: tlsSecretFor(ing.Spec.TLS, host),
+				Problem:   backendProblem(backend, servicesByName, endpointsByName),
+			}
+		}
+		if ing.Spec.Backend != nil {
+			routes = append(routes, newRoute(anyHost, defaultBackend, *ing.Spec.Backend))
+		}
+		for _, rule := range ing.Spec.Rules {
+			host := rule.Host
+			if host == "" {
+				host = anyHost
+			}
+			if rule.HTTP == nil {
+				continue
+			}
+			for _, path := range rule.HTTP.Paths {
+				p := path.Path
+				if p == "" {
+					p = anyPath
+				}
+				routes = append(routes, newRoute(host, p, path.Backend))
+			}
+		}
+	}
+	sort.SliceStable(routes, func(i, j int) bool {
+		if routes[i].Ingress != routes[j].Ingress {
+			return routes[i].Ingress < routes[j].Ingress
+		}
+		return routes[i].Host < routes[j].Host
+	})
+	return
+}
+
+// tlsSecretFor returns the secret terminating TLS for a host, if any
+func tlsSecretFor(tls []networkingv1beta1.IngressTLS, host string) string {
+	for _, t := range tls {
+		// a TLS entry without hosts covers every host of the ingress
+		if len(t.Hosts) == 0 {
+			return t.SecretName
+		}
+		for _, h := range t.Hosts {
+			if h == host || wildcardCovers(h, host) {
+				return t.SecretName
+			}
+		}
+	}
+	return ""
+}
+
+// wildcardCovers is true when a wildcard TLS host such as *.example.com
+// covers a host. The wildcard stands for exactly one label, so it covers
+// a.example.com but not example.com or a.b.example.com.
+func wildcardCovers(wildcard, host string) bool {
+	if !strings.HasPrefix(wildcard, "*.") || !strings.HasSuffix(host, wildcard[1:]) {
+		return false
+	}
+	label := strings.TrimSuffix(host, wildcard[1:])
+	return label != "" && !strings.Contains(label, ".")
+}
+
+// backendProblem checks that the service an ingress points at exists,
+// exposes the port and has ready endpoints behind it
+func backendProblem(backend networkingv1beta1.IngressBackend, services map[string]*corev1.Service, endpoints map[string]*corev1.Endpoints) string {
+	svc, ok := services[backend.ServiceName]
+	if !ok {
+		return "Service not found"
+	}
+	if !servicePortExists(svc, backend.ServicePort) {
+		return fmt.Sprintf("Service has no port %s", backend.ServicePort.String())
+	}
+	// external names resolve through DNS and never have endpoints
+	if svc.Spec.Type == corev1.ServiceTypeExternalName {
+		return ""
+	}
+	ep, ok := endpoints[backend.ServiceName]
+	if !ok {
+		return "Endpoints not found"
+	}
+	for _, subset := range ep.Subsets {
+		if len(subset.Addresses) > 0 {
+			return ""
+		}
+	}
+	return "No ready endpoints"
+}
+
+func servicePortExists(svc *corev1.Service, port intstr.IntOrString) bool {
+	// external name services don't need to declare their ports
+	if svc.Spec.Type == corev1.ServiceTypeExternalName {
+		return true
+	}
+	for _, p := range svc.Spec.Ports {
+		if port.Type == intstr.Int && p.Port == port.IntVal {
+			return true
+		}
+		if port.Type == intstr.String && p.Name == port.StrVal {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,33 @@
+package k8sutils
+
+import (
+	"testing"
+
+	networkingv1beta1 "k8s.io/api/networking/v1beta1"
+)
+
+func TestTLSSecretFor(t *testing.T) {
+	tls := []networkingv1beta1.IngressTLS{
+		{Hosts: []string{"exact.example.org"}, SecretName: "exact"},
+		{Hosts: []string{"*.example.com"}, SecretName: "wildcard"},
+	}
+	for _, tc := range []struct {
+		host   string
+		secret string
+	}{
+		{"exact.example.org", "exact"},
+		{"other.example.org", ""},
+		{"a.example.com", "wildcard"},
+		{"a.b.example.com", ""},
+		{"example.com", ""},
+		{".example.com", ""},
+		{"aexample.com", ""},
+	} {
+		if got := tlsSecretFor(tls, tc.host); got != tc.secret {
+			t.Errorf("tlsSecretFor(%q) = %q, expected %q", tc.host, got, tc.secret)
+		}
+	}
+	if got := tlsSecretFor([]networkingv1beta1.IngressTLS{{SecretName: "all"}}, "any.host"); got != "all" {
+		t.Errorf("expected a TLS entry without hosts to cover every host, got %q", got)
+	}
+}
@@ -16,13 +16,13 @@ func (k *kubeFactory) ListNamespaces() (namespaces []string, err error) {
 	return
 }
 
-func (k *kubeFactory) ListPods(ns string) (pods []string, err error) {
+func (k *kubeFactory) ListPods(ns string) (pods []PodSummary, err error) {
 	res, err := k.clientset.CoreV1().Pods(ns).List(v1.ListOptions{})
 	if err != nil {
 		return
 	}
 	for _, po := range res.Items {
-		pods = append(pods, po.Name)
+		pods = append(pods, newPodSummary(&po))
 	}
 	return
 }
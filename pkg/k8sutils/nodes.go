@@ -0,0 +1,197 @@
+package k8sutils
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
+)
+
+const (
+	nodeRoleLabelPrefix = "node-role.kubernetes.io/"
+	nodeRoleLabel       = "kubernetes.io/role"
+)
+
+// NodeSummary holds what `kubectl get nodes` and `kubectl describe node`
+// show about a node, with its resources already formatted
+type NodeSummary struct {
+	Name           string
+	Status         string
+	Roles          string
+	KubeletVersion string
+	Created        time.Time
+	Unschedulable  bool
+	Taints         []string
+	Conditions     []string
+	Addresses      []string
+	Pods           int
+
+	CPURequests       string
+	CPUAllocatable    string
+	CPUPercent        int64
+	MemoryRequests    string
+	MemoryAllocatable string
+	MemoryPercent     int64
+}
+
+// Ready is true when the node's Ready condition is true
+func (n NodeSummary) Ready() bool {
+	return strings.HasPrefix(n.Status, string(corev1.NodeReady))
+}
+
+// ListNodes returns every node in the cluster along with the sum of the
+// resource requests of the pods running on it
+func (k *kubeFactory) ListNodes() (nodes []NodeSummary, err error) {
+	res, err := k.clientset.CoreV1().Nodes().List(v1.ListOptions{})
+	if err != nil {
+		return
+	}
+	pods, err := k.listActivePods(fields.Everything())
+	if err != nil {
+		return
+	}
+	byNode := make(map[string][]corev1.Pod)
+	for _, pod := range pods {
+		byNode[pod.Spec.NodeName] = append(byNode[pod.Spec.NodeName], pod)
+	}
+	for idx := range res.Items {
+		node := &res.Items[idx]
+		nodes = append(nodes, newNodeSummary(node, byNode[node.Name]))
+	}
+	sort.Slice(nodes, func(i, j int) bool { return nodes[i].Name < nodes[j].Name })
+	return
+}
+
+// GetNode fetches a single node and the pods running on it
+func (k *kubeFactory) GetNode(name string) (*NodeSummary, error) {
+	node, err := k.clientset.CoreV1().Nodes().Get(name, v1.GetOptions{})
+	if err != nil {
+		return nil, err
+	}
+	pods, err := k.listActivePods(fields.OneTermEqualSelector("spec.nodeName", name))
+	if err != nil {
+		return nil, err
+	}
+	summary := newNodeSummary(node, pods)
+	return &summary, nil
+}
+
+// listActivePods lists the pods in every namespace that have not finished,
+// which are the ones still holding on to their resource requests
+func (k *kubeFactory) listActivePods(selector fields.Selector) ([]corev1.Pod, error) {
+	selector = fields.AndSelectors(
+		selector,
+		fields.OneTermNotEqualSelector("status.phase", string(corev1.PodSucceeded)),
+		fields.OneTermNotEqualSelector("status.phase", string(corev1.PodFailed)),
+	)
+	res, err := k.clientset.CoreV1().Pods("").List(v1.ListOptions{FieldSelector: selector.String()})
+	if err != nil {
+		return nil, err
+	}
+	return res.Items, nil
+}
+
+func newNodeSummary(node *corev1.Node, pods []corev1.Pod) NodeSummary {
+	summary := NodeSummary{
+		Name:           node.Name,
+		Status:         "Unknown",
+		KubeletVersion: node.Status.NodeInfo.KubeletVersion,
+		Created:        node.CreationTimestamp.Time,
+		Unschedulable:  node.Spec.Unschedulable,
+		Taints:         make([]string, 0),
+		Conditions:     make([]string, 0),
+		Addresses:      make([]string, 0),
+		Pods:           len(pods),
+	}
+
+	for _, cond := range node.Status.Conditions {
+		if cond.Type == corev1.NodeReady {
+			if cond.Status == corev1.ConditionTrue {
+				summary.Status = string(corev1.NodeReady)
+			} else if cond.Status == corev1.ConditionFalse {
+				summary.Status = "NotReady"
+			}
+		}
+		summary.Conditions = append(summary.Conditions,
+			fmt.Sprintf("%s=%s  %s  %s", cond.Type, cond.Status, cond.Reason, cond.Message))
+	}
+	if node.Spec.Unschedulable {
+		summary.Status = fmt.Sprintf("%s,SchedulingDisabled", summary.Status)
+	}
+
+	roles := make([]string, 0)
+	for label, value := range node.Labels {
+		if strings.HasPrefix(label, nodeRoleLabelPrefix) {
+			if role := strings.TrimPrefix(label, nodeRoleLabelPrefix); role != "" {
+				roles = append(roles, role)
+			}
+		} else if label == nodeRoleLabel && value != "" {
+			roles = append(roles, value)
+		}
+	}
+	sort.Strings(roles)
+	summary.Roles = strings.Join(roles, ",")
+	if summary.Roles == "" {
+		summary.Roles = "<none>"
+	}
+
+	for _, taint := range node.Spec.Taints {
+		if taint.Value != "" {
+			summary.Taints = append(summary.Taints, fmt.Sprintf("%s=%s:%s", taint.Key, taint.Value, taint.Effect))
+		} else {
+			summary.Taints = append(summary.Taints, fmt.Sprintf("%s:%s", taint.Key, taint.Effect))
+		}
+	}
+	for _, addr := range node.Status.Addresses {
+		summary.Addresses = append(summary.Addresses, fmt.Sprintf("%s: %s", addr.Type, addr.Address))
+	}
+
+	requests := make(corev1.ResourceList)
+	for idx := range pods {
+		addResources(requests, podRequests(&pods[idx]))
+	}
+	cpu, allocCPU := requests[corev1.ResourceCPU], node.Status.Allocatable[corev1.ResourceCPU]
+	mem, allocMem := requests[corev1.ResourceMemory], node.Status.Allocatable[corev1.ResourceMemory]
+	summary.CPURequests, summary.CPUAllocatable = cpu.String(), allocCPU.String()
+	summary.MemoryRequests, summary.MemoryAllocatable = mem.String(), allocMem.String()
+	if allocCPU.MilliValue() > 0 {
+		summary.CPUPercent = cpu.MilliValue() * 100 / allocCPU.MilliValue()
+	}
+	if allocMem.Value() > 0 {
+		summary.MemoryPercent = mem.Value() * 100 / allocMem.Value()
+	}
+	return summary
+}
+
+// podRequests works out what a pod asks the scheduler for: the sum of its
+// containers' requests, or the largest init container request if that
+// is higher, since init containers run one at a time before the rest
+func podRequests(pod *corev1.Pod) corev1.ResourceList {
+	requests := make(corev1.ResourceList)
+	for _, container := range pod.Spec.Containers {
+		addResources(requests, container.Resources.Requests)
+	}
+	for _, container := range pod.Spec.InitContainers {
+		for name, quantity := range container.Resources.Requests {
+			if current, ok := requests[name]; !ok || quantity.Cmp(current) > 0 {
+				requests[name] = quantity.DeepCopy()
+			}
+		}
+	}
+	return requests
+}
+
+func addResources(total, add corev1.ResourceList) {
+	for name, quantity := range add {
+		if current, ok := total[name]; ok {
+			current.Add(quantity)
+			total[name] = current
+		} else {
+			total[name] = quantity.DeepCopy()
+		}
+	}
+}
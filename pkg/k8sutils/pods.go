@@ -0,0 +1,147 @@
+package k8sutils
+
+import (
+	"fmt"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	policyv1beta1 "k8s.io/api/policy/v1beta1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// nodeUnreachablePodReason is the reason the node controller sets on pods
+// it is deleting from a node it has lost contact with
+const nodeUnreachablePodReason = "NodeLost"
+
+// PodSummary holds the columns shown by `kubectl get pods -o wide`
+type PodSummary struct {
+	Name            string
+	Namespace       string
+	ReadyContainers int
+	Containers      int
+	Status          string
+	Restarts        int32
+	Created         time.Time
+	IP              string
+	Node            string
+}
+
+// Ready returns the ready column, e.g. 1/2
+func (p PodSummary) Ready() string {
+	return fmt.Sprintf("%d/%d", p.ReadyContainers, p.Containers)
+}
+
+// Healthy is true when the pod is running with every container ready,
+// or has run to completion
+func (p PodSummary) Healthy() bool {
+	switch p.Status {
+	case "Completed", string(corev1.PodSucceeded):
+		return true
+	case string(corev1.PodRunning):
+		return p.ReadyContainers == p.Containers
+	}
+	return false
+}
+
+// newPodSummary works out the status of a pod from its container states
+// the same way kubectl does, so that waiting and terminated reasons like
+// CrashLoopBackOff and OOMKilled surface in place of the pod phase
+func newPodSummary(pod *corev1.Pod) (summary PodSummary) {
+	summary.Name = pod.Name
+	summary.Namespace = pod.Namespace
+	summary.Created = pod.CreationTimestamp.Time
+	summary.IP = pod.Status.PodIP
+	summary.Node = pod.Spec.NodeName
+
+	reason := string(pod.Status.Phase)
+	if pod.Status.Reason != "" {
+		reason = pod.Status.Reason
+	}
+
+	var restarts int32
+	initializing := false
+	for idx, container := range pod.Status.InitContainerStatuses {
+		restarts += container.RestartCount
+		switch {
+		case container.State.Terminated != nil && container.State.Terminated.ExitCode == 0:
+			continue
+		case container.State.Terminated != nil:
+			if container.State.Terminated.Reason != "" {
+				reason = "Init:" + container.State.Terminated.Reason
+			} else if container.State.Terminated.Signal != 0 {
+				reason = fmt.Sprintf("Init:Signal:%d", container.State.Terminated.Signal)
+			} else {
+				reason = fmt.Sprintf("Init:ExitCode:%d", container.State.Terminated.ExitCode)
+			}
+		case container.State.Waiting != nil && container.State.Waiting.Reason != "" && container.State.Waiting.Reason != "PodInitializing":
+			reason = "Init:" + container.State.Waiting.Reason
+		default:
+			reason = fmt.Sprintf("Init:%d/%d", idx, len(pod.Spec.InitContainers))
+		}
+		initializing = true
+		break
+	}
+
+	if !initializing {
+		restarts = 0
+		hasRunning := false
+		for idx := len(pod.Status.ContainerStatuses) - 1; idx >= 0; idx-- {
+			container := pod.Status.ContainerStatuses[idx]
+			restarts += container.RestartCount
+			if container.State.Waiting != nil && container.State.Waiting.Reason != "" {
+				reason = container.State.Waiting.Reason
+			} else if container.State.Terminated != nil && container.State.Terminated.Reason != "" {
+				reason = container.State.Terminated.Reason
+			} else if container.State.Terminated != nil && container.State.Terminated.Signal != 0 {
+				reason = fmt.Sprintf("Signal:%d", container.State.Terminated.Signal)
+			} else if container.State.Terminated != nil {
+				reason = fmt.Sprintf("ExitCode:%d", container.State.Terminated.ExitCode)
+			} else if container.Ready && container.State.Running != nil {
+				hasRunning = true
+				summary.ReadyContainers++
+			}
+		}
+		// a pod with a completed sidecar is still running
+		if reason == "Completed" && hasRunning {
+			reason = string(corev1.PodRunning)
+		}
+	}
+
+	if pod.DeletionTimestamp != nil && pod.Status.Reason == nodeUnreachablePodReason {
+		reason = "Unknown"
+	} else if pod.DeletionTimestamp != nil {
+		reason = "Terminating"
+	}
+
+	summary.Containers = len(pod.Spec.Containers)
+	summary.Status = reason
+	summary.Restarts = restarts
+	return
+}
+
+// DeletePod deletes a pod. A nil grace period leaves it up to the pod spec,
+// while a grace period of 0 force deletes the pod without waiting for the
+// kubelet to confirm its containers have stopped.
+func (k *kubeFactory) DeletePod(ns, pod string, gracePeriod *int64) error {
+	return k.clientset.CoreV1().Pods(ns).Delete(pod, &v1.DeleteOptions{GracePeriodSeconds: gracePeriod})
+}
+
+// EvictPod asks the API server to evict a pod through the eviction
+// subresource, which refuses when it would violate a PodDisruptionBudget
+func (k *kubeFactory) EvictPod(ns, pod string) (err error) {
+	err = k.evictPod(ns, pod)
+	if apierrors.IsTooManyRequests(err) {
+		err = fmt.Errorf("Eviction of %s blocked by a PodDisruptionBudget: %v", pod, err)
+	}
+	return
+}
+
+func (k *kubeFactory) evictPod(ns, pod string) error {
+	return k.clientset.PolicyV1beta1().Evictions(ns).Evict(&policyv1beta1.Eviction{
+		ObjectMeta: v1.ObjectMeta{
+			Name:      pod,
+			Namespace: ns,
+		},
+	})
+}
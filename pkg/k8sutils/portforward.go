@@ -0,0 +1,217 @@
+package k8sutils
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+
+	corev1 "k8s.io/api/core/v1"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/httpstream"
+	"k8s.io/client-go/tools/portforward"
+	"k8s.io/client-go/transport/spdy"
+)
+
+// ErrLostConnection is what a port forward ends with when the connection
+// to the pod goes away, usually because the pod did
+var ErrLostConnection = errors.New("Lost connection to pod")
+
+// PortForward is a running forward of local ports to a pod
+type PortForward struct {
+	Namespace string
+	Pod       string
+	Ports     []portforward.ForwardedPort
+
+	sent     int64
+	received int64
+	stop     chan struct{}
+	stopOnce sync.Once
+	done     chan struct{}
+	err      error
+}
+
+// Stop closes the local listeners and the connection to the pod
+func (p *PortForward) Stop() {
+	p.stopOnce.Do(func() {
+		close(p.stop)
+	})
+}
+
+// Done is closed once the forward has ended, stopped or not
+func (p *PortForward) Done() <-chan struct{} {
+	return p.done
+}
+
+// Err is why the forward ended, or nil if it was stopped
+func (p *PortForward) Err() error {
+	return p.err
+}
+
+// Bytes returns how much has been sent to and received from the pod
+func (p *PortForward) Bytes() (sent, received int64) {
+	return atomic.LoadInt64(&p.sent), atomic.LoadInt64(&p.received)
+}
+
+// PortForward forwards local ports to a pod, over the same SPDY transport
+// exec uses. Ports are given the way kubectl takes them: "8080:80", "80"
+// for the same port on both ends, or ":80" for any free local port. It
+// returns once the local ports are listening.
+func (k *kubeFactory) PortForward(ns, pod string, ports []string) (*PortForward, error) {
+	req := k.clientset.CoreV1().RESTClient().Post().
+		Resource("pods").
+		Name(pod).
+		Namespace(ns).
+		SubResource("portforward")
+	transport, upgrader, err := spdy.RoundTripperFor(k.conf)
+	if err != nil {
+		return nil, err
+	}
+	dialer := spdy.NewDialer(upgrader, &http.Client{Transport: transport}, "POST", req.URL())
+
+	forward := &PortForward{
+		Namespace: ns,
+		Pod:       pod,
+		stop:      make(chan struct{}),
+		done:      make(chan struct{}),
+	}
+	ready := make(chan struct{})
+	fw, err := portforward.New(&countingDialer{Dialer: dialer, forward: forward}, ports, forward.stop, ready, ioutil.Discard, ioutil.Discard)
+	if err != nil {
+		return nil, err
+	}
+	go func() {
+		defer close(forward.done)
+		forward.err = fw.ForwardPorts()
+		if forward.err == nil {
+			// ForwardPorts returns nil both when stopped and when the
+			// connection is lost
+			select {
+			case <-forward.stop:
+			default:
+				forward.err = ErrLostConnection
+			}
+		}
+	}()
+
+	select {
+	case <-ready:
+		forward.Ports, err = fw.GetPorts()
+		return forward, err
+	case <-forward.done:
+		return nil, forward.err
+	}
+}
+
+// ResolveServicePod picks a ready pod behind a service to forward to, and
+// translates service ports into the ports of that pod, the way kubectl
+// port-forward does for services. Any pod other than avoid is picked
+// first, so that forwards don't keep going back to a pod that just failed.
+func (k *kubeFactory) ResolveServicePod(ns, service string, ports []string, avoid string) (pod string, podPorts []string, err error) {
+	svc, err := k.clientset.CoreV1().Services(ns).Get(service, v1.GetOptions{})
+	if err != nil {
+		return
+	}
+	endpoints, err := k.clientset.CoreV1().Endpoints(ns).Get(service, v1.GetOptions{})
+	if err != nil {
+		return
+	}
+	var fallback *corev1.EndpointSubset
+	for idx, subset := range endpoints.Subsets {
+		for _, addr := range subset.Addresses {
+			if addr.TargetRef == nil || addr.TargetRef.Kind != "Pod" {
+				continue
+			}
+			if addr.TargetRef.Name == avoid {
+				fallback = &endpoints.Subsets[idx]
+				continue
+			}
+			podPorts, err = servicePortsToPod(svc, subset, ports)
+			return addr.TargetRef.Name, podPorts, err
+		}
+	}
+	if fallback != nil {
+		podPorts, err = servicePortsToPod(svc, *fallback, ports)
+		return avoid, podPorts, err
+	}
+	return "", nil, fmt.Errorf("Service %s has no ready pods to forward to", service)
+}
+
+func servicePortsToPod(svc *corev1.Service, subset corev1.EndpointSubset, ports []string) ([]string, error) {
+	podPorts := make([]string, 0)
+	for _, spec := range ports {
+		local, remote := spec, spec
+		if idx := strings.Index(spec, ":"); idx >= 0 {
+			local, remote = spec[:idx], spec[idx+1:]
+		}
+		number, err := strconv.Atoi(remote)
+		if err != nil {
+			return nil, fmt.Errorf("Invalid port %q", spec)
+		}
+		target := int32(0)
+		for _, port := range svc.Spec.Ports {
+			if port.Port != int32(number) {
+				continue
+			}
+			for _, epPort := range subset.Ports {
+				if epPort.Name == port.Name {
+					target = epPort.Port
+				}
+			}
+		}
+		if target == 0 {
+			return nil, fmt.Errorf("Service %s has no port %d", svc.Name, number)
+		}
+		podPorts = append(podPorts, fmt.Sprintf("%s:%d", local, target))
+	}
+	return podPorts, nil
+}
+
+// countingDialer counts the bytes moving through the data streams of a
+// port forward, which the PortForwarder doesn't keep track of
+type countingDialer struct {
+	httpstream.Dialer
+	forward *PortForward
+}
+
+func (d *countingDialer) Dial(protocols ...string) (httpstream.Connection, string, error) {
+	conn, protocol, err := d.Dialer.Dial(protocols...)
+	if err != nil {
+		return nil, protocol, err
+	}
+	return &countingConnection{Connection: conn, forward: d.forward}, protocol, nil
+}
+
+type countingConnection struct {
+	httpstream.Connection
+	forward *PortForward
+}
+
+func (c *countingConnection) CreateStream(headers http.Header) (httpstream.Stream, error) {
+	stream, err := c.Connection.CreateStream(headers)
+	if err != nil || headers.Get(corev1.StreamType) != corev1.StreamTypeData {
+		return stream, err
+	}
+	return &countingStream{Stream: stream, forward: c.forward}, nil
+}
+
+type countingStream struct {
+	httpstream.Stream
+	forward *PortForward
+}
+
+func (s *countingStream) Read(p []byte) (int, error) {
+	n, err := s.Stream.Read(p)
+	atomic.AddInt64(&s.forward.received, int64(n))
+	return n, err
+}
+
+func (s *countingStream) Write(p []byte) (int, error) {
+	n, err := s.Stream.Write(p)
+	atomic.AddInt64(&s.forward.sent, int64(n))
+	return n, err
+}
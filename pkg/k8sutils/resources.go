@@ -0,0 +1,134 @@
+package k8sutils
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/discovery"
+)
+
+// APIResource is a type of object served by the API server, builtin or
+// from a CustomResourceDefinition
+type APIResource struct {
+	Group      string
+	Version    string
+	Resource   string
+	Kind       string
+	Namespaced bool
+}
+
+// ObjectSummary holds the columns shown for any object in the resource
+// browser
+type ObjectSummary struct {
+	Name      string
+	Namespace string
+	Created   time.Time
+}
+
+// String returns the resource the way kubectl accepts it, e.g.
+// deployments.apps
+func (r APIResource) String() string {
+	if r.Group == "" {
+		return r.Resource
+	}
+	return fmt.Sprintf("%s.%s", r.Resource, r.Group)
+}
+
+// GroupVersionResource returns the resource as the dynamic client addresses
+// it
+func (r APIResource) GroupVersionResource() schema.GroupVersionResource {
+	return schema.GroupVersionResource{Group: r.Group, Version: r.Version, Resource: r.Resource}
+}
+
+// ListAPIResources returns every resource type the server lets us list and
+// get, at the preferred version of its group. Core resources come first,
+// then the rest by group. Groups whose discovery fails, usually because an
+// aggregated API server is down, are left out rather than failing the rest.
+func (k *kubeFactory) ListAPIResources() (resources []APIResource, err error) {
+	lists, err := k.clientset.Discovery().ServerPreferredResources()
+	if err != nil && !discovery.IsGroupDiscoveryFailedError(err) {
+		return nil, err
+	}
+	lists = discovery.FilteredBy(discovery.SupportsAllVerbs{Verbs: []string{"list", "get"}}, lists)
+	for _, list := range lists {
+		gv, err := schema.ParseGroupVersion(list.GroupVersion)
+		if err != nil {
+			continue
+		}
+		for _, res := range list.APIResources {
+			// subresources like pods/log can't be listed on their own
+			if strings.Contains(res.Name, "/") {
+				continue
+			}
+			resources = append(resources, APIResource{
+				Group:      gv.Group,
+				Version:    gv.Version,
+				Resource:   res.Name,
+				Kind:       res.Kind,
+				Namespaced: res.Namespaced,
+			})
+		}
+	}
+	sort.Slice(resources, func(i, j int) bool {
+		if resources[i].Group != resources[j].Group {
+			return resources[i].Group < resources[j].Group
+		}
+		return resources[i].Resource < resources[j].Resource
+	})
+	return resources, nil
+}
+
+// ListObjects lists the objects of any resource type. The namespace is
+// ignored for cluster scoped resources, and an empty namespace lists
+// across all of them.
+func (k *kubeFactory) ListObjects(resource APIResource, ns string) (objects []ObjectSummary, err error) {
+	client := k.dynamic.Resource(resource.GroupVersionResource())
+	var res *unstructured.UnstructuredList
+	if resource.Namespaced {
+		res, err = client.Namespace(ns).List(v1.ListOptions{})
+	} else {
+		res, err = client.List(v1.ListOptions{})
+	}
+	if err != nil {
+		return
+	}
+	for _, obj := range res.Items {
+		objects = append(objects, ObjectSummary{
+			Name:      obj.GetName(),
+			Namespace: obj.GetNamespace(),
+			Created:   obj.GetCreationTimestamp().Time,
+		})
+	}
+	sort.Slice(objects, func(i, j int) bool {
+		if objects[i].Namespace != objects[j].Namespace {
+			return objects[i].Namespace < objects[j].Namespace
+		}
+		return objects[i].Name < objects[j].Name
+	})
+	return
+}
+
+// GetObject fetches any object by resource type, namespace and name
+func (k *kubeFactory) GetObject(resource APIResource, ns, name string) (*unstructured.Unstructured, error) {
+	client := k.dynamic.Resource(resource.GroupVersionResource())
+	if resource.Namespaced {
+		return client.Namespace(ns).Get(name, v1.GetOptions{})
+	}
+	return client.Get(name, v1.GetOptions{})
+}
+
+// UpdateObject replaces an object with an edited copy of it. The copy keeps
+// the resourceVersion it was edited at, so the server refuses it if the
+// object changed in the meantime rather than losing those changes.
+func (k *kubeFactory) UpdateObject(resource APIResource, obj *unstructured.Unstructured) (*unstructured.Unstructured, error) {
+	client := k.dynamic.Resource(resource.GroupVersionResource())
+	if resource.Namespaced {
+		return client.Namespace(obj.GetNamespace()).Update(obj, v1.UpdateOptions{})
+	}
+	return client.Update(obj, v1.UpdateOptions{})
+}
@@ -0,0 +1,101 @@
+package k8sutils
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"time"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+const (
+	revisionAnnotation    = "deployment.kubernetes.io/revision"
+	changeCauseAnnotation = "kubernetes.io/change-cause"
+)
+
+// Revision is one of the replicasets a deployment has rolled out
+type Revision struct {
+	Number      int64
+	ReplicaSet  string
+	ChangeCause string
+	Replicas    int32
+	Created     time.Time
+	Template    corev1.PodTemplateSpec
+}
+
+// ListRevisions returns the rollout history of a deployment, newest first.
+// The pod-template-hash label is dropped from each template so they can
+// be compared with each other and with the deployment.
+func (k *kubeFactory) ListRevisions(ns, deployment string) (revisions []Revision, err error) {
+	d, err := k.GetDeployment(ns, deployment)
+	if err != nil {
+		return
+	}
+	selector, err := v1.LabelSelectorAsSelector(d.Spec.Selector)
+	if err != nil {
+		return
+	}
+	res, err := k.clientset.AppsV1().ReplicaSets(ns).List(v1.ListOptions{LabelSelector: selector.String()})
+	if err != nil {
+		return
+	}
+	for idx := range res.Items {
+		rs := &res.Items[idx]
+		if !v1.IsControlledBy(rs, d) {
+			continue
+		}
+		number, err := strconv.ParseInt(rs.Annotations[revisionAnnotation], 10, 64)
+		if err != nil {
+			continue
+		}
+		template := rs.Spec.Template.DeepCopy()
+		delete(template.Labels, appsv1.DefaultDeploymentUniqueLabelKey)
+		revisions = append(revisions, Revision{
+			Number:      number,
+			ReplicaSet:  rs.Name,
+			ChangeCause: rs.Annotations[changeCauseAnnotation],
+			Replicas:    rs.Status.Replicas,
+			Created:     rs.CreationTimestamp.Time,
+			Template:    *template,
+		})
+	}
+	sort.Slice(revisions, func(i, j int) bool { return revisions[i].Number > revisions[j].Number })
+	return
+}
+
+// RollbackDeployment replaces the pod template of a deployment with the one
+// from an earlier revision, the same way `kubectl rollout undo` does. The
+// deployment controller then rolls it out as a new revision.
+func (k *kubeFactory) RollbackDeployment(ns, deployment string, revision int64) (err error) {
+	revisions, err := k.ListRevisions(ns, deployment)
+	if err != nil {
+		return
+	}
+	var target *Revision
+	for idx := range revisions {
+		if revisions[idx].Number == revision {
+			target = &revisions[idx]
+			break
+		}
+	}
+	if target == nil {
+		return fmt.Errorf("Revision %d of deployment %s not found", revision, deployment)
+	}
+	patch, err := json.Marshal([]map[string]interface{}{
+		{
+			"op":    "replace",
+			"path":  "/spec/template",
+			"value": target.Template,
+		},
+	})
+	if err != nil {
+		return
+	}
+	_, err = k.clientset.AppsV1().Deployments(ns).Patch(deployment, types.JSONPatchType, patch)
+	return
+}
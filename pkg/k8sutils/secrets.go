@@ -0,0 +1,134 @@
+package k8sutils
+
+import (
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"sort"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// SecretSummary holds the columns shown by `kubectl get secrets`
+type SecretSummary struct {
+	Name    string
+	Type    string
+	Keys    int
+	Created time.Time
+}
+
+// CertificateSummary holds the parts of an x509 certificate worth checking
+// when a TLS secret isn't working
+type CertificateSummary struct {
+	Subject     string
+	Issuer      string
+	DNSNames    []string
+	IPAddresses []string
+	NotBefore   time.Time
+	NotAfter    time.Time
+	IsCA        bool
+}
+
+// Expired is true once the certificate is past its NotAfter date
+func (c CertificateSummary) Expired() bool {
+	return time.Now().After(c.NotAfter)
+}
+
+// ListSecrets returns the secrets in a namespace, sorted by name
+func (k *kubeFactory) ListSecrets(ns string) (secrets []SecretSummary, err error) {
+	res, err := k.clientset.CoreV1().Secrets(ns).List(v1.ListOptions{})
+	if err != nil {
+		return
+	}
+	for _, secret := range res.Items {
+		secrets = append(secrets, SecretSummary{
+			Name:    secret.Name,
+			Type:    string(secret.Type),
+			Keys:    len(secret.Data),
+			Created: secret.CreationTimestamp.Time,
+		})
+	}
+	sort.Slice(secrets, func(i, j int) bool { return secrets[i].Name < secrets[j].Name })
+	return
+}
+
+// GetSecret fetches a secret. The values in its Data are already decoded
+// from the base64 they are sent as.
+func (k *kubeFactory) GetSecret(ns, name string) (*corev1.Secret, error) {
+	return k.clientset.CoreV1().Secrets(ns).Get(name, v1.GetOptions{})
+}
+
+// IsCertificateKey is true for the keys of a secret that hold PEM encoded
+// certificates rather than anything private
+func IsCertificateKey(secret *corev1.Secret, key string) bool {
+	return key == corev1.TLSCertKey || (secret.Type == corev1.SecretTypeTLS && key == "ca.crt")
+}
+
+// IsDockerConfigKey is true for the keys of a secret that hold registry
+// credentials
+func IsDockerConfigKey(secret *corev1.Secret, key string) bool {
+	return (secret.Type == corev1.SecretTypeDockerConfigJson && key == corev1.DockerConfigJsonKey) ||
+		(secret.Type == corev1.SecretTypeDockercfg && key == corev1.DockerConfigKey)
+}
+
+// ParseCertificates decodes every certificate in a PEM bundle
+func ParseCertificates(data []byte) ([]CertificateSummary, error) {
+	certs := make([]CertificateSummary, 0)
+	for {
+		var block *pem.Block
+		block, data = pem.Decode(data)
+		if block == nil {
+			break
+		}
+		if block.Type != "CERTIFICATE" {
+			continue
+		}
+		cert, err := x509.ParseCertificate(block.Bytes)
+		if err != nil {
+			return nil, err
+		}
+		summary := CertificateSummary{
+			Subject:     cert.Subject.String(),
+			Issuer:      cert.Issuer.String(),
+			DNSNames:    cert.DNSNames,
+			IPAddresses: make([]string, 0),
+			NotBefore:   cert.NotBefore,
+			NotAfter:    cert.NotAfter,
+			IsCA:        cert.IsCA,
+		}
+		for _, ip := range cert.IPAddresses {
+			summary.IPAddresses = append(summary.IPAddresses, ip.String())
+		}
+		certs = append(certs, summary)
+	}
+	if len(certs) == 0 {
+		return nil, errors.New("No PEM encoded certificates found")
+	}
+	return certs, nil
+}
+
+// DockerConfigRegistries returns the registries a docker config secret
+// holds credentials for, leaving the credentials themselves alone
+func DockerConfigRegistries(secret *corev1.Secret, key string) ([]string, error) {
+	auths := make(map[string]json.RawMessage)
+	if secret.Type == corev1.SecretTypeDockerConfigJson {
+		config := struct {
+			Auths map[string]json.RawMessage `json:"auths"`
+		}{}
+		if err := json.Unmarshal(secret.Data[key], &config); err != nil {
+			return nil, err
+		}
+		auths = config.Auths
+	} else if err := json.Unmarshal(secret.Data[key], &auths); err != nil {
+		return nil, err
+	}
+	registries := make([]string, 0)
+	for registry := range auths {
+		registries = append(registries, registry)
+	}
+	sort.Strings(registries)
+	return registries, nil
+}
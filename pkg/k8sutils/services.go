@@ -0,0 +1,153 @@
+package k8sutils
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+)
+
+// ServiceSummary holds the columns shown by `kubectl get services -o wide`
+type ServiceSummary struct {
+	Name        string
+	Namespace   string
+	Type        string
+	ClusterIP   string
+	ExternalIPs string
+	Ports       string
+	Selector    string
+	Created     time.Time
+}
+
+// EndpointAddress is one address behind a service, along with the pod
+// that it belongs to when there is one
+type EndpointAddress struct {
+	IP    string
+	Ready bool
+	Pod   string
+	Node  string
+	Ports string
+}
+
+// ListServices returns the services in a namespace, sorted by name
+func (k *kubeFactory) ListServices(ns string) (services []ServiceSummary, err error) {
+	res, err := k.clientset.CoreV1().Services(ns).List(v1.ListOptions{})
+	if err != nil {
+		return
+	}
+	for idx := range res.Items {
+		services = append(services, newServiceSummary(&res.Items[idx]))
+	}
+	sort.Slice(services, func(i, j int) bool { return services[i].Name < services[j].Name })
+	return
+}
+
+// ListEndpoints returns the ready and not ready addresses behind a
+// service, ready addresses first. Services without an Endpoints object,
+// such as ExternalName services, have no addresses.
+func (k *kubeFactory) ListEndpoints(ns, service string) (addresses []EndpointAddress, err error) {
+	addresses = make([]EndpointAddress, 0)
+	res, err := k.clientset.CoreV1().Endpoints(ns).Get(service, v1.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		return addresses, nil
+	} else if err != nil {
+		return
+	}
+	for _, subset := range res.Subsets {
+		ports := make([]string, 0)
+		for _, port := range subset.Ports {
+			if port.Name != "" {
+				ports = append(ports, fmt.Sprintf("%s:%d/%s", port.Name, port.Port, port.Protocol))
+			} else {
+				ports = append(ports, fmt.Sprintf("%d/%s", port.Port, port.Protocol))
+			}
+		}
+		for _, addr := range subset.Addresses {
+			addresses = append(addresses, newEndpointAddress(addr, true, ports))
+		}
+		for _, addr := range subset.NotReadyAddresses {
+			addresses = append(addresses, newEndpointAddress(addr, false, ports))
+		}
+	}
+	sort.SliceStable(addresses, func(i, j int) bool { return addresses[i].Ready && !addresses[j].Ready })
+	return
+}
+
+func newEndpointAddress(addr corev1.EndpointAddress, ready bool, ports []string) EndpointAddress {
+	endpoint := EndpointAddress{
+		IP:    addr.IP,
+		Ready: ready,
+		Ports: strings.Join(ports, ","),
+	}
+	if addr.TargetRef != nil && addr.TargetRef.Kind == "Pod" {
+		endpoint.Pod = addr.TargetRef.Name
+	}
+	if addr.NodeName != nil {
+		endpoint.Node = *addr.NodeName
+	}
+	return endpoint
+}
+
+func newServiceSummary(svc *corev1.Service) ServiceSummary {
+	summary := ServiceSummary{
+		Name:        svc.Name,
+		Namespace:   svc.Namespace,
+		Type:        string(svc.Spec.Type),
+		ClusterIP:   svc.Spec.ClusterIP,
+		ExternalIPs: serviceExternalIPs(svc),
+		Selector:    labels.SelectorFromSet(svc.Spec.Selector).String(),
+		Created:     svc.CreationTimestamp.Time,
+	}
+	if summary.ClusterIP == "" {
+		summary.ClusterIP = "<none>"
+	}
+	if len(svc.Spec.Selector) == 0 {
+		summary.Selector = "<none>"
+	}
+	ports := make([]string, 0)
+	for _, port := range svc.Spec.Ports {
+		if port.NodePort != 0 {
+			ports = append(ports, fmt.Sprintf("%d:%d/%s", port.Port, port.NodePort, port.Protocol))
+		} else {
+			ports = append(ports, fmt.Sprintf("%d/%s", port.Port, port.Protocol))
+		}
+	}
+	summary.Ports = strings.Join(ports, ",")
+	if summary.Ports == "" {
+		summary.Ports = "<none>"
+	}
+	return summary
+}
+
+// serviceExternalIPs works out the external IP column the same way
+// kubectl does for each type of service
+func serviceExternalIPs(svc *corev1.Service) string {
+	switch svc.Spec.Type {
+	case corev1.ServiceTypeClusterIP, corev1.ServiceTypeNodePort:
+		if len(svc.Spec.ExternalIPs) > 0 {
+			return strings.Join(svc.Spec.ExternalIPs, ",")
+		}
+	case corev1.ServiceTypeLoadBalancer:
+		ips := make([]string, 0)
+		for _, ingress := range svc.Status.LoadBalancer.Ingress {
+			if ingress.IP != "" {
+				ips = append(ips, ingress.IP)
+			} else if ingress.Hostname != "" {
+				ips = append(ips, ingress.Hostname)
+			}
+		}
+		ips = append(ips, svc.Spec.ExternalIPs...)
+		if len(ips) > 0 {
+			return strings.Join(ips, ",")
+		}
+		return "<pending>"
+	case corev1.ServiceTypeExternalName:
+		return svc.Spec.ExternalName
+	}
+	return "<none>"
+}
@@ -0,0 +1,107 @@
+package k8sutils
+
+import (
+	"context"
+	"sort"
+
+	corev1 "k8s.io/api/core/v1"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/cache"
+)
+
+// PodFilter narrows the pods returned by WatchPods
+type PodFilter struct {
+	LabelSelector string
+	FieldSelector string
+}
+
+// WatchPods streams summaries of the pods in a namespace (or every
+// namespace when ns is empty) that match the filter. A fresh list, sorted
+// by namespace and name, is sent every time a pod is added, deleted or
+// updated. The watch is backed by an informer, so when the server drops the
+// connection it is resumed from the last seen resourceVersion (or relisted
+// if that version has expired). The channel is closed when the context is
+// cancelled.
+func (k *kubeFactory) WatchPods(ns string, filter PodFilter, ctx context.Context) (<-chan []PodSummary, error) {
+	applyFilter := func(opts *v1.ListOptions) {
+		opts.LabelSelector = filter.LabelSelector
+		opts.FieldSelector = filter.FieldSelector
+	}
+	// the informer retries failed lists forever, so check we can actually
+	// list pods here to give the caller an error to show
+	opts := v1.ListOptions{Limit: 1}
+	applyFilter(&opts)
+	if _, err := k.clientset.CoreV1().Pods(ns).List(opts); err != nil {
+		return nil, err
+	}
+	lw := cache.NewFilteredListWatchFromClient(k.clientset.CoreV1().RESTClient(), "pods", ns, applyFilter)
+	snapshots := watchResource(ctx, lw, &corev1.Pod{})
+	pods := make(chan []PodSummary)
+	go func() {
+		defer close(pods)
+		for objs := range snapshots {
+			summaries := make([]PodSummary, 0)
+			for _, obj := range objs {
+				summaries = append(summaries, newPodSummary(obj.(*corev1.Pod)))
+			}
+			sort.Slice(summaries, func(i, j int) bool {
+				if summaries[i].Namespace != summaries[j].Namespace {
+					return summaries[i].Namespace < summaries[j].Namespace
+				}
+				return summaries[i].Name < summaries[j].Name
+			})
+			select {
+			case pods <- summaries:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return pods, nil
+}
+
+// watchResource runs an informer for the given ListerWatcher until the
+// context is cancelled, sending the contents of its store whenever it
+// changes. Bursts of changes are coalesced so a slow reader only ever
+// sees the latest state.
+func watchResource(ctx context.Context, lw cache.ListerWatcher, objType runtime.Object) <-chan []interface{} {
+	changed := make(chan struct{}, 1)
+	notify := func() {
+		select {
+		case changed <- struct{}{}:
+		default:
+		}
+	}
+	store, informer := cache.NewInformer(lw, objType, 0, cache.ResourceEventHandlerFuncs{
+		AddFunc:    func(interface{}) { notify() },
+		UpdateFunc: func(interface{}, interface{}) { notify() },
+		DeleteFunc: func(interface{}) { notify() },
+	})
+	go informer.Run(ctx.Done())
+	// an empty store never fires a handler, so signal once after the
+	// initial list completes
+	go func() {
+		if cache.WaitForCacheSync(ctx.Done(), informer.HasSynced) {
+			notify()
+		}
+	}()
+
+	out := make(chan []interface{})
+	go func() {
+		defer close(out)
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-changed:
+				select {
+				case out <- store.List():
+				case <-ctx.Done():
+					return
+				}
+			}
+		}
+	}()
+	return out
+}
@@ -0,0 +1,290 @@
+package k8sutils
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"time"
+
+	appsv1 "k8s.io/api/apps/v1"
+	autoscalingv1 "k8s.io/api/autoscaling/v1"
+	corev1 "k8s.io/api/core/v1"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// restartedAtAnnotation is the pod template annotation kubectl uses to
+// trigger a rollout restart
+const restartedAtAnnotation = "kubectl.kubernetes.io/restartedAt"
+
+// Workload kinds understood by ListWorkloads and GetWorkload
+const (
+	KindDeployment  = "Deployment"
+	KindStatefulSet = "StatefulSet"
+	KindDaemonSet   = "DaemonSet"
+	KindReplicaSet  = "ReplicaSet"
+)
+
+// WorkloadSummary holds the replica counts and rollout settings shared by
+// the workload controllers
+type WorkloadSummary struct {
+	Kind      string
+	Name      string
+	Namespace string
+	Desired   int32
+	Current   int32
+	Ready     int32
+	Updated   int32
+	Available int32
+	Strategy  string
+	Selector  string
+	Images    []string
+	Created   time.Time
+}
+
+// ListWorkloads returns the deployments, statefulsets, daemonsets and
+// replicasets in a namespace, grouped by kind and sorted by name
+func (k *kubeFactory) ListWorkloads(ns string) (workloads []WorkloadSummary, err error) {
+	apps := k.clientset.AppsV1()
+	deployments, err := apps.Deployments(ns).List(v1.ListOptions{})
+	if err != nil {
+		return
+	}
+	for _, d := range deployments.Items {
+		workloads = append(workloads, deploymentSummary(&d))
+	}
+	statefulsets, err := apps.StatefulSets(ns).List(v1.ListOptions{})
+	if err != nil {
+		return
+	}
+	for _, s := range statefulsets.Items {
+		workloads = append(workloads, statefulSetSummary(&s))
+	}
+	daemonsets, err := apps.DaemonSets(ns).List(v1.ListOptions{})
+	if err != nil {
+		return
+	}
+	for _, d := range daemonsets.Items {
+		workloads = append(workloads, daemonSetSummary(&d))
+	}
+	replicasets, err := apps.ReplicaSets(ns).List(v1.ListOptions{})
+	if err != nil {
+		return
+	}
+	for _, r := range replicasets.Items {
+		workloads = append(workloads, replicaSetSummary(&r))
+	}
+	sort.SliceStable(workloads, func(i, j int) bool {
+		if workloads[i].Kind == workloads[j].Kind {
+			return workloads[i].Name < workloads[j].Name
+		}
+		return kindOrder(workloads[i].Kind) < kindOrder(workloads[j].Kind)
+	})
+	return
+}
+
+// GetWorkload fetches a single workload of the given kind and summarizes it
+func (k *kubeFactory) GetWorkload(ns, kind, name string) (workload *WorkloadSummary, err error) {
+	var summary WorkloadSummary
+	switch kind {
+	case KindDeployment:
+		res, err := k.GetDeployment(ns, name)
+		if err != nil {
+			return nil, err
+		}
+		summary = deploymentSummary(res)
+	case KindStatefulSet:
+		res, err := k.GetStatefulSet(ns, name)
+		if err != nil {
+			return nil, err
+		}
+		summary = statefulSetSummary(res)
+	case KindDaemonSet:
+		res, err := k.GetDaemonSet(ns, name)
+		if err != nil {
+			return nil, err
+		}
+		summary = daemonSetSummary(res)
+	case KindReplicaSet:
+		res, err := k.GetReplicaSet(ns, name)
+		if err != nil {
+			return nil, err
+		}
+		summary = replicaSetSummary(res)
+	default:
+		return nil, fmt.Errorf("Unknown workload kind: %s", kind)
+	}
+	workload = &summary
+	return
+}
+
+func (k *kubeFactory) GetDeployment(ns, name string) (*appsv1.Deployment, error) {
+	return k.clientset.AppsV1().Deployments(ns).Get(name, v1.GetOptions{})
+}
+
+func (k *kubeFactory) GetStatefulSet(ns, name string) (*appsv1.StatefulSet, error) {
+	return k.clientset.AppsV1().StatefulSets(ns).Get(name, v1.GetOptions{})
+}
+
+func (k *kubeFactory) GetDaemonSet(ns, name string) (*appsv1.DaemonSet, error) {
+	return k.clientset.AppsV1().DaemonSets(ns).Get(name, v1.GetOptions{})
+}
+
+func (k *kubeFactory) GetReplicaSet(ns, name string) (*appsv1.ReplicaSet, error) {
+	return k.clientset.AppsV1().ReplicaSets(ns).Get(name, v1.GetOptions{})
+}
+
+// ScaleWorkload sets the replica count of a deployment, statefulset or
+// replicaset through its scale subresource
+func (k *kubeFactory) ScaleWorkload(ns, kind, name string, replicas int32) (err error) {
+	apps := k.clientset.AppsV1()
+	var scale *autoscalingv1.Scale
+	switch kind {
+	case KindDeployment:
+		if scale, err = apps.Deployments(ns).GetScale(name, v1.GetOptions{}); err != nil {
+			return
+		}
+		scale.Spec.Replicas = replicas
+		_, err = apps.Deployments(ns).UpdateScale(name, scale)
+	case KindStatefulSet:
+		if scale, err = apps.StatefulSets(ns).GetScale(name, v1.GetOptions{}); err != nil {
+			return
+		}
+		scale.Spec.Replicas = replicas
+		_, err = apps.StatefulSets(ns).UpdateScale(name, scale)
+	case KindReplicaSet:
+		if scale, err = apps.ReplicaSets(ns).GetScale(name, v1.GetOptions{}); err != nil {
+			return
+		}
+		scale.Spec.Replicas = replicas
+		_, err = apps.ReplicaSets(ns).UpdateScale(name, scale)
+	default:
+		err = fmt.Errorf("%s workloads can't be scaled", kind)
+	}
+	return
+}
+
+// RestartWorkload triggers a rollout of a deployment, statefulset or
+// daemonset by stamping its pod template with the current time, the same
+// way `kubectl rollout restart` does
+func (k *kubeFactory) RestartWorkload(ns, kind, name string) (err error) {
+	patch, err := json.Marshal(map[string]interface{}{
+		"spec": map[string]interface{}{
+			"template": map[string]interface{}{
+				"metadata": map[string]interface{}{
+					"annotations": map[string]string{
+						restartedAtAnnotation: time.Now().Format(time.RFC3339),
+					},
+				},
+			},
+		},
+	})
+	if err != nil {
+		return
+	}
+	apps := k.clientset.AppsV1()
+	switch kind {
+	case KindDeployment:
+		_, err = apps.Deployments(ns).Patch(name, types.StrategicMergePatchType, patch)
+	case KindStatefulSet:
+		_, err = apps.StatefulSets(ns).Patch(name, types.StrategicMergePatchType, patch)
+	case KindDaemonSet:
+		_, err = apps.DaemonSets(ns).Patch(name, types.StrategicMergePatchType, patch)
+	default:
+		err = fmt.Errorf("%s workloads can't be restarted", kind)
+	}
+	return
+}
+
+func kindOrder(kind string) int {
+	for idx, k := range []string{KindDeployment, KindStatefulSet, KindDaemonSet, KindReplicaSet} {
+		if k == kind {
+			return idx
+		}
+	}
+	return -1
+}
+
+func deploymentSummary(d *appsv1.Deployment) WorkloadSummary {
+	summary := newWorkloadSummary(KindDeployment, d.ObjectMeta, d.Spec.Selector, d.Spec.Template)
+	summary.Desired = replicasOrDefault(d.Spec.Replicas)
+	summary.Current = d.Status.Replicas
+	summary.Ready = d.Status.ReadyReplicas
+	summary.Updated = d.Status.UpdatedReplicas
+	summary.Available = d.Status.AvailableReplicas
+	summary.Strategy = string(d.Spec.Strategy.Type)
+	if ru := d.Spec.Strategy.RollingUpdate; ru != nil && ru.MaxSurge != nil && ru.MaxUnavailable != nil {
+		summary.Strategy = fmt.Sprintf("%s (max surge %s, max unavailable %s)",
+			summary.Strategy, ru.MaxSurge.String(), ru.MaxUnavailable.String())
+	}
+	return summary
+}
+
+func statefulSetSummary(s *appsv1.StatefulSet) WorkloadSummary {
+	summary := newWorkloadSummary(KindStatefulSet, s.ObjectMeta, s.Spec.Selector, s.Spec.Template)
+	summary.Desired = replicasOrDefault(s.Spec.Replicas)
+	summary.Current = s.Status.Replicas
+	summary.Ready = s.Status.ReadyReplicas
+	summary.Updated = s.Status.UpdatedReplicas
+	// statefulsets don't report availability, ready is the closest thing
+	summary.Available = s.Status.ReadyReplicas
+	summary.Strategy = string(s.Spec.UpdateStrategy.Type)
+	if ru := s.Spec.UpdateStrategy.RollingUpdate; ru != nil && ru.Partition != nil {
+		summary.Strategy = fmt.Sprintf("%s (partition %d)", summary.Strategy, *ru.Partition)
+	}
+	return summary
+}
+
+func daemonSetSummary(d *appsv1.DaemonSet) WorkloadSummary {
+	summary := newWorkloadSummary(KindDaemonSet, d.ObjectMeta, d.Spec.Selector, d.Spec.Template)
+	summary.Desired = d.Status.DesiredNumberScheduled
+	summary.Current = d.Status.CurrentNumberScheduled
+	summary.Ready = d.Status.NumberReady
+	summary.Updated = d.Status.UpdatedNumberScheduled
+	summary.Available = d.Status.NumberAvailable
+	summary.Strategy = string(d.Spec.UpdateStrategy.Type)
+	if ru := d.Spec.UpdateStrategy.RollingUpdate; ru != nil && ru.MaxUnavailable != nil {
+		summary.Strategy = fmt.Sprintf("%s (max unavailable %s)", summary.Strategy, ru.MaxUnavailable.String())
+	}
+	return summary
+}
+
+func replicaSetSummary(r *appsv1.ReplicaSet) WorkloadSummary {
+	summary := newWorkloadSummary(KindReplicaSet, r.ObjectMeta, r.Spec.Selector, r.Spec.Template)
+	summary.Desired = replicasOrDefault(r.Spec.Replicas)
+	summary.Current = r.Status.Replicas
+	summary.Ready = r.Status.ReadyReplicas
+	// every pod of a replicaset runs its template, so they are all current
+	summary.Updated = r.Status.Replicas
+	summary.Available = r.Status.AvailableReplicas
+	for _, owner := range r.OwnerReferences {
+		summary.Strategy = fmt.Sprintf("Managed by %s/%s", owner.Kind, owner.Name)
+	}
+	return summary
+}
+
+func newWorkloadSummary(kind string, meta v1.ObjectMeta, selector *v1.LabelSelector, template corev1.PodTemplateSpec) WorkloadSummary {
+	summary := WorkloadSummary{
+		Kind:      kind,
+		Name:      meta.Name,
+		Namespace: meta.Namespace,
+		Created:   meta.CreationTimestamp.Time,
+		Images:    make([]string, 0),
+	}
+	if sel, err := v1.LabelSelectorAsSelector(selector); err == nil {
+		summary.Selector = sel.String()
+	}
+	for _, container := range template.Spec.Containers {
+		summary.Images = append(summary.Images, container.Image)
+	}
+	return summary
+}
+
+// replicasOrDefault dereferences a replica count, which the API server
+// defaults to 1 when it is left unset
+func replicasOrDefault(replicas *int32) int32 {
+	if replicas == nil {
+		return 1
+	}
+	return *replicas
+}
@@ -15,7 +15,7 @@ import (
 	"golang.org/x/crypto/ssh"
 )
 
-func (s *server) handleChannel(newChannel ssh.NewChannel) {
+func (s *server) handleChannel(conn *ssh.ServerConn, newChannel ssh.NewChannel) {
 	// Since we're handling a shell, we expect a
 	// channel type of "session". The also describes
 	// "x11", "direct-tcpip" and "forwarded-tcpip"
@@ -35,12 +35,15 @@ func (s *server) handleChannel(newChannel ssh.NewChannel) {
 
 	// start up the console
 	os.Setenv("TERM", "xterm")
-	var console *exec.Cmd
+	args := make([]string, 0)
 	if s.incluster {
-		console = exec.Command(os.Args[0], "-cluster")
-	} else {
-		console = exec.Command(os.Args[0])
+		args = append(args, "-cluster")
 	}
+	// the console audits what is done in it as whoever connected. Clients
+	// don't authenticate, so this is the name they gave and where they
+	// came from.
+	args = append(args, "-user", fmt.Sprintf("ssh:%s@%s", conn.User(), conn.RemoteAddr()))
+	console := exec.Command(os.Args[0], append(args, s.consoleArgs...)...)
 
 	// Prepare teardown function
 	close := func() {
@@ -83,13 +86,29 @@ func (s *server) handleChannel(newChannel ssh.NewChannel) {
 					req.Reply(true, nil)
 				}
 			case "pty-req":
-				termLen := req.Payload[3]
+				// the payload starts with the TERM value as a
+				// length-prefixed string
+				if len(req.Payload) < 4 {
+					req.Reply(false, nil)
+					continue
+				}
+				termLen := binary.BigEndian.Uint32(req.Payload)
+				if uint64(len(req.Payload)) < uint64(termLen)+12 {
+					req.Reply(false, nil)
+					continue
+				}
 				w, h := parseDims(req.Payload[termLen+4:])
 				SetWinsize(consolef.Fd(), w, h)
 				// Responding true (OK) here will let the client
 				// know we have a pty ready for input
 				req.Reply(true, nil)
 			case "window-change":
+				// resizing the pty sends the console a SIGWINCH,
+				// which resizes its panes and any exec session
+				// running in it
+				if len(req.Payload) < 8 {
+					continue
+				}
 				w, h := parseDims(req.Payload)
 				SetWinsize(consolef.Fd(), w, h)
 			}
@@ -18,12 +18,15 @@ type Server interface {
 
 type server struct {
 	Server
-	key       ssh.Signer
-	incluster bool
+	key         ssh.Signer
+	incluster   bool
+	consoleArgs []string
 }
 
-func New(incluster bool, serverkey string) (Server, error) {
-	s := &server{incluster: incluster}
+// New returns an SSH server starting a console for every session, passing
+// consoleArgs on to it
+func New(incluster bool, serverkey string, consoleArgs []string) (Server, error) {
+	s := &server{incluster: incluster, consoleArgs: consoleArgs}
 	var key ssh.Signer
 	var err error
 	if serverkey == "" {
@@ -63,7 +66,7 @@ func (s *server) Listen() (err error) {
 		go ssh.DiscardRequests(reqs)
 
 		for newChannel := range chans {
-			go s.handleChannel(newChannel)
+			go s.handleChannel(conn, newChannel)
 		}
 	}
 }
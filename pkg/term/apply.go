@@ -0,0 +1,313 @@
+package term
+
+import (
+	"fmt"
+	"strings"
+
+	ui "github.com/gizak/termui/v3"
+	"github.com/tinyzimmer/kubeconsole/pkg/k8sutils"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"sigs.k8s.io/yaml"
+)
+
+const (
+	applyTitle     = " Apply "
+	applyPlanning  = "Comparing manifests against the cluster..."
+	applyDiffTitle = " Changes "
+	applyDiffHint  = "<enter> on an object shows what applying it changes"
+
+	applyCreate    = "create"
+	applyUpdate    = "update"
+	applyUnchanged = "unchanged"
+	applyInvalid   = "invalid"
+)
+
+var applyColumns = []string{"ACTION", "KIND", "NAMESPACE", "NAME", "RESULT"}
+
+// applyStep is an object read from a manifest, and what applying it does
+// according to a dry run against the live state
+type applyStep struct {
+	resource *k8sutils.APIResource
+	object   *unstructured.Unstructured
+	action   string
+	diff     [][]span
+	result   string
+}
+
+// newApplyList dry runs every object against the cluster to work out what
+// applying them will change
+func (c *controller) newApplyList(path string, objects []*unstructured.Unstructured) (t *selectableTable) {
+	t = newSelectableTable(applyColumns...)
+	t.Title = fmt.Sprintf(" %s   %s ", applyTitle, path)
+	t.TextStyle = ui.NewStyle(ui.ColorCyan)
+	x, y := ui.TerminalDimensions()
+	t.SetRect(0, 3, x, y/3)
+	t.Data = [][]string{{applyPlanning}}
+
+	c.applySteps = nil
+	go func() {
+		steps := make([]applyStep, 0)
+		for _, obj := range objects {
+			steps = append(steps, c.planApply(obj))
+		}
+		c.applySteps = steps
+		t.Data, t.DataStyles = applyRows(steps)
+		if t == c.applyList {
+			c.mux.Lock()
+			ui.Render(t)
+			c.mux.Unlock()
+		}
+	}()
+	return
+}
+
+func newApplyDiffWindow() *styledList {
+	pane := newStyledList()
+	pane.Title = applyDiffTitle
+	pane.Rows = plainRows([]string{applyDiffHint}, ui.NewStyle(ui.ColorWhite))
+	x, y := ui.TerminalDimensions()
+	pane.SetRect(0, y/3, x, y-3)
+	return pane
+}
+
+// planApply resolves the resource type of an object and dry runs applying
+// it. Namespaced objects without a namespace go to the current one, like
+// kubectl does with the namespace of the context.
+func (c *controller) planApply(obj *unstructured.Unstructured) (step applyStep) {
+	step = applyStep{object: obj, action: applyInvalid}
+	resource, err := c.factory.ResolveResource(obj.GetAPIVersion(), obj.GetKind())
+	if err != nil {
+		step.result = err.Error()
+		return
+	}
+	step.resource = resource
+	if !resource.Namespaced {
+		obj.SetNamespace("")
+	} else if obj.GetNamespace() == "" {
+		ns := c.currentNamespace
+		if ns == "" {
+			ns = "default"
+		}
+		obj.SetNamespace(ns)
+	}
+
+	before, after, err := c.factory.ApplyObject(*resource, obj, true)
+	if err != nil {
+		step.result = err.Error()
+		return
+	}
+	afterLines, err := comparableLines(after)
+	if err != nil {
+		step.result = err.Error()
+		return
+	}
+	if before == nil {
+		step.action = applyCreate
+		step.diff = diffRows(nil, maskedLines(after, nil))
+		return
+	}
+	beforeLines, err := comparableLines(before)
+	if err != nil {
+		step.result = err.Error()
+		return
+	}
+	step.action = applyUnchanged
+	step.diff = diffRows(maskedLines(before, nil), maskedLines(after, before))
+	if strings.Join(beforeLines, "\n") != strings.Join(afterLines, "\n") {
+		step.action = applyUpdate
+	}
+	return
+}
+
+// comparableLines renders an object as YAML lines without the fields the
+// server changes on every write
+func comparableLines(obj *unstructured.Unstructured) ([]string, error) {
+	obj = obj.DeepCopy()
+	unstructured.RemoveNestedField(obj.Object, "metadata", "managedFields")
+	unstructured.RemoveNestedField(obj.Object, "metadata", "resourceVersion")
+	unstructured.RemoveNestedField(obj.Object, "metadata", "generation")
+	out, err := yaml.Marshal(obj.Object)
+	if err != nil {
+		return nil, err
+	}
+	return strings.Split(strings.TrimSpace(string(out)), "\n"), nil
+}
+
+// maskedLines are the comparableLines of an object with Secret values
+// masked, marking those that differ from previous. The object has already
+// been rendered unmasked, so it can't fail to render.
+func maskedLines(obj, previous *unstructured.Unstructured) []string {
+	obj = obj.DeepCopy()
+	maskSecretData(obj, previous)
+	lines, _ := comparableLines(obj)
+	return lines
+}
+
+// applyRows formats the apply plan into table rows, coloured by action
+func applyRows(steps []applyStep) (rows [][]string, styles map[int]ui.Style) {
+	rows = make([][]string, 0)
+	styles = make(map[int]ui.Style)
+	for idx, step := range steps {
+		rows = append(rows, []string{
+			step.action,
+			step.object.GetKind(),
+			step.object.GetNamespace(),
+			step.object.GetName(),
+			step.result,
+		})
+		switch step.action {
+		case applyCreate:
+			styles[idx] = ui.NewStyle(ui.ColorGreen)
+		case applyUpdate:
+			styles[idx] = ui.NewStyle(ui.ColorYellow)
+		case applyInvalid:
+			styles[idx] = ui.NewStyle(ui.ColorRed)
+		}
+	}
+	return
+}
+
+// renderApply renders the panes of the apply view
+func (c *controller) renderApply() {
+	c.mux.Lock()
+	defer c.mux.Unlock()
+	ui.Render(
+		c.navWindow,
+		c.serverWindow,
+		c.helpWindow,
+		c.applyList,
+		c.applyDiff,
+	)
+}
+
+func (c *controller) getSelectedApplyStep() *applyStep {
+	if c.applyList == nil || c.applyList.SelectedRow >= len(c.applySteps) {
+		return nil
+	}
+	return &c.applySteps[c.applyList.SelectedRow]
+}
+
+// showApplyDiff shows what applying the selected object changes
+func (c *controller) showApplyDiff() {
+	step := c.getSelectedApplyStep()
+	if step == nil {
+		return
+	}
+	c.applyDiff.Title = fmt.Sprintf(" %s   %s/%s ", applyDiffTitle, step.object.GetKind(), step.object.GetName())
+	if step.action == applyInvalid {
+		c.applyDiff.Rows = plainRows([]string{step.result}, ui.NewStyle(ui.ColorRed))
+	} else {
+		c.applyDiff.Rows = step.diff
+	}
+	c.applyDiff.ScrollTop()
+}
+
+// applyChanges applies every object that creates or changes something,
+// once confirmed
+func (c *controller) applyChanges() (q string) {
+	var creates, updates int
+	for _, step := range c.applySteps {
+		switch step.action {
+		case applyCreate:
+			creates++
+		case applyUpdate:
+			updates++
+		}
+	}
+	if creates+updates == 0 {
+		c.errorChan <- newErrorWithStack(fmt.Errorf("Nothing to apply, every object is unchanged or invalid"))
+		return
+	}
+	q = c.confirmPrompt(fmt.Sprintf("Create %d and update %d objects?", creates, updates))
+	if q != confirmYes {
+		return
+	}
+	for idx := range c.applySteps {
+		step := &c.applySteps[idx]
+		if step.action != applyCreate && step.action != applyUpdate {
+			continue
+		}
+		name := fmt.Sprintf("%s %s/%s", step.resource, step.object.GetNamespace(), step.object.GetName())
+		before, _, err := c.factory.ApplyObject(*step.resource, step.object, false)
+		switch {
+		case err != nil:
+			c.debug(fmt.Sprintf("Failed to apply %s: %v", name, err))
+			step.result = err.Error()
+		case before == nil:
+			c.debug(fmt.Sprintf("Created %s", name))
+			step.result = "created"
+		default:
+			c.debug(fmt.Sprintf("Configured %s", name))
+			step.result = "configured"
+		}
+	}
+	c.applyList.Data, c.applyList.DataStyles = applyRows(c.applySteps)
+	return ""
+}
+
+// pollApply reads the manifests at a path the user gives and previews
+// applying them, until the user backs out to the resource browser
+func (c *controller) pollApply() (q string) {
+	path := c.inputPrompt(" Apply manifests from file or directory ", "", nil)
+	if path == _cancel || path == _quit || path == "" {
+		return path
+	}
+	path = expandHome(path)
+	objects, err := k8sutils.ReadManifests(path)
+	if err != nil {
+		c.errorChan <- newErrorWithStack(err)
+		return
+	}
+	c.debug(fmt.Sprintf("Read %d objects from %s", len(objects), path))
+
+	c.helpWindow.Text = applyHelpText
+	c.applyList = c.newApplyList(path, objects)
+	c.applyDiff = newApplyDiffWindow()
+	var focus scrollable = c.applyList
+	defer func() {
+		c.applyList = nil
+		c.helpWindow.Text = resourcesHelpText
+	}()
+
+	for {
+		ui.Clear()
+		c.renderApply()
+		e := <-c.events
+		switch e.ID {
+
+		// read the manifests again and compare them with what is live now
+		case "r":
+			if objects, err = k8sutils.ReadManifests(path); err != nil {
+				c.errorChan <- newErrorWithStack(err)
+				continue
+			}
+			c.applyList = c.newApplyList(path, objects)
+			c.applyDiff = newApplyDiffWindow()
+			focus = c.applyList
+
+		case "<Escape>":
+			return
+
+		case "<Tab>":
+			if focus == c.applyList {
+				focus = c.applyDiff
+			} else {
+				focus = c.applyList
+			}
+
+		case enter:
+			c.showApplyDiff()
+
+		case "a":
+			if q = c.applyChanges(); q == _quit {
+				return
+			}
+			focus = c.applyList
+
+		default:
+			if q = c.checkCommon(focus, e.ID); q == _quit {
+				return
+			}
+		}
+	}
+}
@@ -15,14 +15,54 @@ const (
 	detailsTitle = " Details "
 	logTitle     = " Logs "
 	helpTitle    = " Help "
-	helpText     = "<q>uit | <r>efresh | <e>xec/pod | <t>ail logs | <s>witch context | <tab> switch panes"
+	helpText     = "<q>uit | <r>efresh | <e>xec shell | <E>xec command | <t>ail logs | <d>elete/evict | <C>opy files | <F>orward ports | <a>ll pods | <y> manifest | <[>/<]>/<X> sessions | <tab> switch panes | <?> more keys"
 	consoleTitle = " Console "
-	execTitle    = " Exec  Ctrl-D to exit "
+	execTitle    = " Exec "
 	errorTitle   = "ERROR"
-	clearEvent   = "CLEAR"
+	execHelpText = "<C-]> detach, leaving the session running | exit the program to close the session"
+	keysTitle    = " Keys  any key to close "
+
+	namespacesHelpText = "<q>uit | <r>efresh | <enter> pods in namespace | <s>witch context | <?> more keys"
+	consoleHelpText    = "<q>uit | <?> more keys"
+	workloadsHelpText  = "<q>uit | <r>efresh | <enter> details | <f>ilter pods by workload | <s>cale | <R>ollout restart | rollout <h>istory | <?> more keys"
+	eventsHelpText     = "<q>uit | <r>efresh | <?> more keys"
+	servicesHelpText   = "<q>uit | <r>efresh | <enter> endpoints/jump to pod | <f>ilter pods by service | <F>orward ports | <tab> switch panes | <?> more keys"
+	ingressesHelpText  = "<q>uit | <r>efresh | <enter> jump to service | <?> more keys"
+	configMapsHelpText = "<q>uit | <r>efresh | <enter> keys/value | <e>dit key in $EDITOR | <tab> switch panes | <?> more keys"
+	secretsHelpText    = "<q>uit | <r>efresh | <enter> keys/masked value | <x> reveal key | <tab> switch panes | <?> more keys"
+	nodesHelpText      = "<q>uit | <r>efresh | <enter> details | <f>ilter pods by node | <C>ordon/uncordon | <D>rain | <?> more keys"
+	resourcesHelpText  = "<q>uit | <r>efresh | <enter> objects/manifest | <e>dit in $EDITOR | <A>pply manifests | </> find resource type | <tab> switch panes | <?> more keys"
+	forwardsHelpText   = "<q>uit | <r>efresh | <s>tart forward to pod/svc | <x> stop forward | <?> more keys"
+	applyHelpText      = "<q>uit | <r>e-read and compare | <enter> changes | <a>pply | <tab> switch panes | <esc> back"
+	historyHelpText    = "<q>uit | <r>efresh | <enter> diff | <m>ark revision to diff against | <u>ndo to revision | <tab> switch panes | <esc> back"
+
+	// keysText lists the keys that work in every view, which don't fit in
+	// the help pane
+	keysText = `Switch views  <n>amespaces  <p>ods  <w>orkloads  serv<i>ces  in<g>resses  config<m>aps
+              <S>ecrets  reso<u>rces  e<v>ents  n<o>des  <F>orwards  <c>onsole
+Scroll        <up>/<down>  <pgup>/<pgdn>  <home>/<end>
+Manifests     <J>SON/YAML  <M>anaged fields  </> search  <N>ext match
+Sessions      <enter> resume/attach  <[>/<]> switch  <X> close  <C-]> detach
+              <q>uit  <?> these keys`
 )
 
-var tabPanes = []string{"[N]amespaces", "[P]ods", "[C]onsole"}
+var tabPanes = []string{"[N]amespaces", "[P]ods", "[W]orkloads", "Serv[i]ces", "In[g]resses", "Config[M]aps", "[S]ecrets", "Reso[u]rces", "E[v]ents", "N[o]des", "[F]orwards", "[C]onsole"}
+
+// indexes into tabPanes
+const (
+	namespacesTab = iota
+	podsTab
+	workloadsTab
+	servicesTab
+	ingressesTab
+	configMapsTab
+	secretsTab
+	resourcesTab
+	eventsTab
+	nodesTab
+	forwardsTab
+	consoleTab
+)
 
 func newErrorWindow() *widgets.Paragraph {
 	pane := widgets.NewParagraph()
@@ -40,11 +80,28 @@ func newNavWindow(debug bool) *widgets.TabPane {
 	if debug {
 		pane.Title = fmt.Sprintf("%s - DEBUGGING TO FILE ", pane.Title)
 	}
-	x, _ := ui.TerminalDimensions()
-	pane.SetRect(0, 0, x/2, 3)
+	pane.SetRect(0, 0, navWidth(), 3)
 	return pane
 }
 
+// navWidth fits the nav window to its tabs, leaving the rest of the top row
+// to the server window but never less than half of it
+func navWidth() int {
+	// each tab is drawn followed by a separator and padding, plus the borders
+	width := 2
+	for _, name := range tabPanes {
+		width += len(name) + 3
+	}
+	x, _ := ui.TerminalDimensions()
+	if width < x/2 {
+		width = x / 2
+	}
+	if width > x {
+		width = x
+	}
+	return width
+}
+
 func (c *controller) newAPIServerWindow() *widgets.Paragraph {
 	version, err := c.factory.APIVersion()
 	if err != nil {
@@ -55,7 +112,7 @@ func (c *controller) newAPIServerWindow() *widgets.Paragraph {
 	pane.Text = fmt.Sprintf(serverFormat, c.factory.APIHost(), version)
 	pane.TextStyle = ui.NewStyle(ui.ColorGreen)
 	x, _ := ui.TerminalDimensions()
-	pane.SetRect(x/2, 0, x, 3)
+	pane.SetRect(navWidth(), 0, x, 3)
 	return pane
 }
 
@@ -68,6 +125,17 @@ func newHelpWindow() *widgets.Paragraph {
 	return par
 }
 
+// newKeysWindow lists the keys that work in every view
+func newKeysWindow() *widgets.Paragraph {
+	pane := widgets.NewParagraph()
+	pane.Title = keysTitle
+	pane.Text = keysText
+	pane.TextStyle = ui.NewStyle(ui.ColorCyan)
+	x, y := ui.TerminalDimensions()
+	pane.SetRect(x/8, y/2-5, x-x/8, y/2+5)
+	return pane
+}
+
 func newDetailsWindow() (*widgets.List, chan string) {
 	ch := make(chan string)
 	par := widgets.NewList()
@@ -100,14 +168,10 @@ func newConsoleWindow() *widgets.List {
 	return par
 }
 
-func newExecWindow() *widgets.List {
-	//ex := widgets.NewList()
-	ex := widgets.NewList()
+func newExecWindow(screen *vtScreen) *terminalPane {
+	ex := newTerminalPane(screen)
 	ex.Title = execTitle
-	x, y := ui.TerminalDimensions()
-	ex.SetRect(0, y/2, x, y-3)
-	ex.TextStyle = ui.NewStyle(ui.ColorWhite)
-	ex.WrapText = true
-	ex.SelectedRowStyle = ui.NewStyle(ui.ColorWhite)
+	ex.SetRect(sessionRect())
+	ex.Fit()
 	return ex
 }
@@ -0,0 +1,280 @@
+package term
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+	"strings"
+
+	ui "github.com/gizak/termui/v3"
+	corev1 "k8s.io/api/core/v1"
+)
+
+const (
+	configMapsTitle   = " ConfigMaps "
+	configMapsLoading = "Loading configmaps..."
+	configMapsNoNS    = "Select a namespace first"
+	configKeysTitle   = " Keys "
+	configKeysLoading = "Loading keys..."
+	configKeysHint    = "<enter> on a configmap lists its keys"
+	configValueTitle  = " Value "
+	configValueHint   = "<enter> on a key shows its value"
+)
+
+var (
+	configMapColumns = []string{"NAME", "DATA", "AGE"}
+	configKeyColumns = []string{"KEY", "SIZE"}
+)
+
+func (c *controller) newConfigMapList() (t *selectableTable) {
+	t = newSelectableTable(configMapColumns...)
+	t.Title = fmt.Sprintf(" %s   Namespace: %s ", configMapsTitle, c.currentNamespace)
+	t.TextStyle = ui.NewStyle(ui.ColorCyan)
+	x, y := ui.TerminalDimensions()
+	t.SetRect(0, 3, x/2, y/2)
+
+	c.configMaps = nil
+	if c.currentNamespace == "" {
+		t.Data = [][]string{{configMapsNoNS}}
+		return
+	}
+	t.Data = [][]string{{configMapsLoading}}
+
+	go func() {
+		configMaps, err := c.factory.ListConfigMaps(c.currentNamespace)
+		if err != nil {
+			c.errorChan <- newErrorWithStack(err)
+			return
+		}
+		c.debug(fmt.Sprintf("Retrieved %d configmaps in %s", len(configMaps), c.currentNamespace))
+		c.configMaps = configMaps
+		t.Data = make([][]string, 0)
+		for _, cm := range configMaps {
+			t.Data = append(t.Data, []string{cm.Name, fmt.Sprintf("%d", cm.Keys), age(cm.Created)})
+		}
+		if c.navWindow.ActiveTabIndex == configMapsTab {
+			c.mux.Lock()
+			ui.Render(t)
+			c.mux.Unlock()
+		}
+	}()
+	return
+}
+
+// newConfigKeyList lists the keys of a configmap, or a hint on how to get
+// there when name is empty. The configmap is kept so that edits are made
+// against the version that was shown.
+func (c *controller) newConfigKeyList(name string) (t *selectableTable) {
+	t = newSelectableTable(configKeyColumns...)
+	t.Title = configKeysTitle
+	t.TextStyle = ui.NewStyle(ui.ColorCyan)
+	x, y := ui.TerminalDimensions()
+	t.SetRect(x/2, 3, x, y/2)
+
+	c.configMap = nil
+	c.configKeys = nil
+	if name == "" {
+		t.Data = [][]string{{configKeysHint}}
+		return
+	}
+	t.Title = fmt.Sprintf(" %s   ConfigMap: %s ", configKeysTitle, name)
+	t.Data = [][]string{{configKeysLoading}}
+
+	go func() {
+		cm, err := c.factory.GetConfigMap(c.currentNamespace, name)
+		if err != nil {
+			c.errorChan <- newErrorWithStack(err)
+			return
+		}
+		c.configMap, c.configKeys = cm, configMapKeys(cm)
+		t.Data = configKeyRows(cm, c.configKeys)
+		if t == c.configKeyList && c.navWindow.ActiveTabIndex == configMapsTab {
+			c.mux.Lock()
+			ui.Render(t)
+			c.mux.Unlock()
+		}
+	}()
+	return
+}
+
+func newConfigValueWindow() *styledList {
+	pane := newStyledList()
+	pane.Title = configValueTitle
+	pane.Rows = plainRows([]string{configValueHint}, ui.NewStyle(ui.ColorWhite))
+	x, y := ui.TerminalDimensions()
+	pane.SetRect(0, y/2, x, y-3)
+	return pane
+}
+
+// configMapKeys returns the keys of a configmap in order, text keys and
+// binary keys together
+func configMapKeys(cm *corev1.ConfigMap) []string {
+	keys := make([]string, 0)
+	for key := range cm.Data {
+		keys = append(keys, key)
+	}
+	for key := range cm.BinaryData {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func configKeyRows(cm *corev1.ConfigMap, keys []string) [][]string {
+	rows := make([][]string, 0)
+	for _, key := range keys {
+		if value, ok := cm.Data[key]; ok {
+			rows = append(rows, []string{key, fmt.Sprintf("%d bytes", len(value))})
+		} else {
+			rows = append(rows, []string{key, fmt.Sprintf("%d bytes (binary)", len(cm.BinaryData[key]))})
+		}
+	}
+	return rows
+}
+
+// renderConfigMaps renders the panes of the configmaps view
+func (c *controller) renderConfigMaps() {
+	c.mux.Lock()
+	defer c.mux.Unlock()
+	ui.Render(
+		c.navWindow,
+		c.serverWindow,
+		c.helpWindow,
+		c.configMapList,
+		c.configKeyList,
+		c.configValue,
+	)
+}
+
+func (c *controller) getSelectedConfigMap() string {
+	if c.configMapList == nil || c.configMapList.SelectedRow >= len(c.configMaps) {
+		return ""
+	}
+	return c.configMaps[c.configMapList.SelectedRow].Name
+}
+
+func (c *controller) getSelectedConfigKey() string {
+	if c.configKeyList == nil || c.configMap == nil || c.configKeyList.SelectedRow >= len(c.configKeys) {
+		return ""
+	}
+	return c.configKeys[c.configKeyList.SelectedRow]
+}
+
+// showConfigValue shows the value of the selected key
+func (c *controller) showConfigValue() {
+	key := c.getSelectedConfigKey()
+	if key == "" {
+		return
+	}
+	c.configValue.Title = fmt.Sprintf(" %s   %s ", configValueTitle, key)
+	if value, ok := c.configMap.Data[key]; ok {
+		c.configValue.Rows = plainRows(strings.Split(value, "\n"), ui.NewStyle(ui.ColorWhite))
+	} else {
+		c.configValue.Rows = plainRows([]string{
+			fmt.Sprintf("%d bytes of binary data", len(c.configMap.BinaryData[key])),
+		}, ui.NewStyle(ui.ColorYellow))
+	}
+	c.configValue.ScrollTop()
+}
+
+// editConfigValue opens the selected key in $EDITOR and saves the result
+// back to the configmap it was loaded from
+func (c *controller) editConfigValue() (q string) {
+	key := c.getSelectedConfigKey()
+	if key == "" {
+		return
+	}
+	cm := c.configMap
+	value, ok := cm.Data[key]
+	if !ok {
+		c.errorChan <- newErrorWithStack(fmt.Errorf("%s is binary data and can't be edited as text", key))
+		return
+	}
+	edited, err := c.runEditor(key, []byte(value))
+	if err == errLostTerminal {
+		return _quit
+	} else if err != nil {
+		c.errorChan <- newErrorWithStack(err)
+		return
+	}
+	if bytes.Equal(edited, []byte(value)) {
+		c.debug(fmt.Sprintf("No changes made to %s in %s", key, cm.Name))
+		return
+	}
+
+	update := cm.DeepCopy()
+	update.Data[key] = string(edited)
+	c.debug(fmt.Sprintf("Updating %s in configmap %s at resourceVersion %s", key, cm.Name, cm.ResourceVersion))
+	updated, err := c.factory.UpdateConfigMap(update)
+	if err != nil {
+		c.debug(fmt.Sprintf("Failed to update configmap %s: %v", cm.Name, err))
+		c.errorChan <- newErrorWithStack(err)
+		return
+	}
+	c.debug(fmt.Sprintf("Updated %s in configmap %s", key, cm.Name))
+	c.configMap = updated
+	c.configKeyList.Data = configKeyRows(updated, c.configKeys)
+	c.showConfigValue()
+	return
+}
+
+func (c *controller) pollConfigMaps() (next int) {
+	c.focusTab(configMapsTab)
+	c.helpWindow.Text = configMapsHelpText
+	c.configMapList = c.newConfigMapList()
+	c.configKeyList = c.newConfigKeyList("")
+	c.configValue = newConfigValueWindow()
+	var focus scrollable = c.configMapList
+	for {
+		ui.Clear()
+		c.renderConfigMaps()
+		e := <-c.events
+		switch e.ID {
+
+		// reload
+		case "r":
+			c.configMapList = c.newConfigMapList()
+			c.configKeyList = c.newConfigKeyList("")
+			c.configValue = newConfigValueWindow()
+			focus = c.configMapList
+
+		case "<Tab>":
+			switch focus {
+			case c.configMapList:
+				focus = c.configKeyList
+			case c.configKeyList:
+				focus = c.configValue
+			default:
+				focus = c.configMapList
+			}
+
+		// list the keys of a configmap, or show the value of a key
+		case enter:
+			if focus == c.configMapList {
+				if name := c.getSelectedConfigMap(); name != "" {
+					c.configKeyList = c.newConfigKeyList(name)
+					c.configValue = newConfigValueWindow()
+					focus = c.configKeyList
+				}
+			} else if focus == c.configKeyList {
+				c.showConfigValue()
+			}
+
+		// edit the selected key
+		case "e":
+			if focus == c.configKeyList || focus == c.configValue {
+				if q := c.editConfigValue(); q == _quit {
+					return quitView
+				}
+			}
+
+		default:
+			if next, ok := c.switchView(e.ID); ok {
+				return next
+			}
+			if q := c.checkCommon(focus, e.ID); q == _quit {
+				return quitView
+			}
+		}
+	}
+}
@@ -10,6 +10,7 @@ import (
 	"github.com/gizak/termui/v3/widgets"
 	"github.com/tinyzimmer/kubeconsole/pkg/k8sutils"
 	"golang.org/x/crypto/ssh/terminal"
+	corev1 "k8s.io/api/core/v1"
 )
 
 // Controller is the exported controller interface
@@ -22,44 +23,120 @@ type controller struct {
 
 	factory k8sutils.KubernetesFactory
 
-	navWindow     *widgets.TabPane
-	helpWindow    *widgets.Paragraph
-	serverWindow  *widgets.Paragraph
-	namespaceList *widgets.List
-	podList       *widgets.List
-	detailsWindow *widgets.List
-	logWindow     *widgets.List
-	console       *widgets.List
-	execWindow    *widgets.List
-	errorWindow   *widgets.Paragraph
+	navWindow         *widgets.TabPane
+	helpWindow        *widgets.Paragraph
+	serverWindow      *widgets.Paragraph
+	namespaceList     *widgets.List
+	podList           *selectableTable
+	workloadList      *selectableTable
+	revisionList      *selectableTable
+	revisionDiff      *styledList
+	eventList         *selectableTable
+	eventMessage      *widgets.Paragraph
+	serviceList       *selectableTable
+	endpointList      *selectableTable
+	ingressList       *selectableTable
+	configMapList     *selectableTable
+	configKeyList     *selectableTable
+	configValue       *styledList
+	secretList        *selectableTable
+	secretKeyList     *selectableTable
+	secretValue       *styledList
+	resourceTypeList  *selectableTable
+	objectList        *selectableTable
+	objectManifest    *styledList
+	applyList         *selectableTable
+	applyDiff         *styledList
+	nodeList          *selectableTable
+	nodeDetails       *widgets.List
+	forwardList       *selectableTable
+	detailsWindow     *widgets.List
+	podManifestWindow *styledList
+	sessionTabs       *widgets.TabPane
+	noSessions        *widgets.List
+	console           *widgets.List
+	workloadDetails   *widgets.List
+	errorWindow       *widgets.Paragraph
 
 	currentNamespace string
+	podWatchCancel   func()
+	podFilter        k8sutils.PodFilter
+	pods             []k8sutils.PodSummary
+	podToSelect      string
+	workloads        []k8sutils.WorkloadSummary
+	services         []k8sutils.ServiceSummary
+	endpoints        []k8sutils.EndpointAddress
+	serviceToSelect  string
+	ingressRoutes    []k8sutils.IngressRoute
+	configMaps       []k8sutils.ConfigMapSummary
+	configMap        *corev1.ConfigMap
+	configKeys       []string
+	secrets          []k8sutils.SecretSummary
+	secret           *corev1.Secret
+	secretKeys       []string
+	apiResources     []k8sutils.APIResource
+	apiResource      *k8sutils.APIResource
+	objects          []k8sutils.ObjectSummary
+	applySteps       []applyStep
+	revisions        []k8sutils.Revision
+	markedRevision   int64
+	eventSummaries   []k8sutils.EventSummary
+	eventWatchCancel func()
+	nodes            []k8sutils.NodeSummary
+	drainCancel      func()
+	forwards         []*portForward
+	sessions         []*session
+
+	// full manifests, and how they are shown in every view
+	podManifest           *manifest
+	resourceManifest      *manifest
+	showPodManifest       bool
+	manifestAsJSON        bool
+	manifestManagedFields bool
+
+	// ends the session once the views have quit, when something went
+	// wrong that the console can't carry on from
+	fatalErr error
 
 	consoleFocused bool
 	debugToFile    bool
+	record         RecordOptions
+	// who is using the console, for the audit log
+	user string
+
+	detailsChan         chan string
+	workloadDetailsChan chan string
+	nodeDetailsChan     chan string
+	debugChan           chan string
+	errorChan           chan *errorWithStack
 
-	logChan     chan string
-	detailsChan chan string
-	debugChan   chan string
-	errorChan   chan *errorWithStack
+	// every poll loop and prompt reads from the same event stream, so
+	// keystrokes can't be swallowed by a loop that isn't in front
+	events <-chan ui.Event
 
 	mux       sync.Mutex
 	resizemux sync.Mutex
 }
 
 // New returns a new terminal ui controller
-func New(factory k8sutils.KubernetesFactory, debug bool) Controller {
+func New(factory k8sutils.KubernetesFactory, debug bool, record RecordOptions, user string) Controller {
 	c := &controller{factory: factory}
 	c.debugToFile = debug
+	c.record = record
+	c.user = user
 	c.errorChan = make(chan *errorWithStack)
 	c.debugChan = make(chan string)
 	c.navWindow = newNavWindow(c.debugToFile)
 	c.serverWindow = c.newAPIServerWindow()
 	c.helpWindow = newHelpWindow()
 	c.detailsWindow, c.detailsChan = newDetailsWindow()
-	c.logWindow, c.logChan = c.newLogWindow()
+	c.podManifestWindow = newPodManifestWindow()
+	c.workloadDetails, c.workloadDetailsChan = newWorkloadDetailsWindow()
+	c.eventMessage = newEventMessageWindow()
+	c.nodeDetails, c.nodeDetailsChan = newNodeDetailsWindow()
+	c.sessionTabs = newSessionTabs()
+	c.noSessions = newNoSessionsWindow()
 	c.console = newConsoleWindow()
-	c.execWindow = newExecWindow()
 	c.errorWindow = newErrorWindow()
 	return c
 }
@@ -67,6 +144,7 @@ func New(factory k8sutils.KubernetesFactory, debug bool) Controller {
 func (c *controller) Run() error {
 	c.debug(fmt.Sprintf("Connected to %s", c.factory.APIHost()))
 	c.debug("Starting handlers")
+	c.events = ui.PollEvents()
 	// listen on the error channel
 	go c.listenForErrors()
 	//  handle terminal resizes - work in progress
@@ -83,8 +161,8 @@ func (c *controller) Run() error {
 	// render defaults and bring up namespace prompt
 	c.debug("Starting poll loops")
 	c.renderDefaults()
-	c.pollNamespaces(selectionChan)
-	return nil
+	c.runViews(c.pollNamespaces(selectionChan))
+	return c.fatalErr
 }
 
 // renderDefaults renders the default panes
@@ -96,11 +174,47 @@ func (c *controller) renderDefaults() {
 		c.serverWindow,
 		c.helpWindow,
 		c.podList,
-		c.detailsWindow,
-		c.logWindow,
+		c.podDetailsPane(),
+		c.sessionTabs,
+		c.sessionPane(),
 	)
 }
 
+// renderView renders the panes of whichever tab is active, for drawing
+// behind prompts
+func (c *controller) renderView() {
+	switch c.navWindow.ActiveTabIndex {
+	case workloadsTab:
+		if c.revisionList != nil {
+			c.renderHistory()
+		} else {
+			c.renderWorkloads()
+		}
+	case servicesTab:
+		c.renderServices()
+	case ingressesTab:
+		c.renderIngresses()
+	case configMapsTab:
+		c.renderConfigMaps()
+	case secretsTab:
+		c.renderSecrets()
+	case resourcesTab:
+		if c.applyList != nil {
+			c.renderApply()
+		} else {
+			c.renderResources()
+		}
+	case eventsTab:
+		c.renderEvents()
+	case nodesTab:
+		c.renderNodes()
+	case forwardsTab:
+		c.renderForwards()
+	default:
+		c.renderDefaults()
+	}
+}
+
 // resizeDefaults is my hacky resizer for now. It essentially starts
 // a brand new session, saving current values where appropriate.
 // This current causes some bugginess after the resize. You basically need
@@ -109,14 +223,16 @@ func (c *controller) renderDefaults() {
 func (c *controller) resizeDefaults() {
 	c.resizemux.Lock()
 	defer c.resizemux.Unlock()
+	activeTab := c.navWindow.ActiveTabIndex
 	c.navWindow = newNavWindow(c.debugToFile)
+	c.focusTab(activeTab)
 	c.serverWindow = c.newAPIServerWindow()
 	c.helpWindow = newHelpWindow()
 
 	ch := make(chan string)
 	c.podList = c.newPodList(ch)
-	if c.currentNamespace != "" {
-		ch <- c.currentNamespace
+	if c.currentNamespace != "" || c.podFilter.FieldSelector != "" {
+		ch <- c.podNamespace()
 	} else {
 		c.namespaceList = c.newNamespaceList()
 	}
@@ -129,13 +245,65 @@ func (c *controller) resizeDefaults() {
 	c.detailsWindow, c.detailsChan = newDetailsWindow()
 	c.detailsWindow.Rows = detailsBak
 
-	execBak := c.execWindow.Rows
-	c.execWindow = newExecWindow()
-	c.execWindow.Rows = execBak
+	c.podManifestWindow = newPodManifestWindow()
+	if c.podManifest != nil {
+		c.drawManifest(c.podManifestWindow, c.podManifest)
+	}
 
-	logBak := c.logWindow.Rows
-	c.logWindow, c.logChan = c.newLogWindow()
-	c.logWindow.Rows = logBak
+	c.resizeSessions()
+
+	workloadDetailsBak := c.workloadDetails.Rows
+	c.workloadDetails, c.workloadDetailsChan = newWorkloadDetailsWindow()
+	c.workloadDetails.Rows = workloadDetailsBak
+	if c.workloadList != nil {
+		c.workloadList = c.newWorkloadList()
+	}
+
+	if c.serviceList != nil {
+		c.serviceList = c.newServiceList()
+		c.endpointList = c.newEndpointList("")
+	}
+
+	if c.ingressList != nil {
+		c.ingressList = c.newIngressList()
+	}
+
+	if c.configMapList != nil {
+		c.configMapList = c.newConfigMapList()
+		c.configKeyList = c.newConfigKeyList("")
+		c.configValue = newConfigValueWindow()
+	}
+
+	if c.secretList != nil {
+		c.secretList = c.newSecretList()
+		c.secretKeyList = c.newSecretKeyList("")
+		c.secretValue = newSecretValueWindow()
+	}
+
+	if c.resourceTypeList != nil {
+		c.resourceTypeList = c.newResourceTypeList()
+		c.objectList = c.newObjectList(c.apiResource)
+		c.objectManifest = newManifestWindow()
+		if c.resourceManifest != nil {
+			c.drawManifest(c.objectManifest, c.resourceManifest)
+		}
+	}
+
+	c.eventMessage = newEventMessageWindow()
+	if activeTab == eventsTab {
+		c.eventList = c.newEventList()
+	}
+
+	nodeDetailsBak := c.nodeDetails.Rows
+	c.nodeDetails, c.nodeDetailsChan = newNodeDetailsWindow()
+	c.nodeDetails.Rows = nodeDetailsBak
+	if c.nodeList != nil {
+		c.nodeList = c.newNodeList()
+	}
+
+	if c.forwardList != nil {
+		c.forwardList = c.newForwardList()
+	}
 
 	ui.Clear()
 	c.renderDefaults()
@@ -159,12 +327,6 @@ func (c *controller) renderConsole() {
 	ui.Render(c.console)
 }
 
-// reset the Log Window
-func (c *controller) resetLogWindow() {
-	c.debug("Resetting log window")
-	c.logWindow, c.logChan = c.newLogWindow()
-}
-
 // listen on the error channel and bring up a prompt when
 // any get raised
 func (c *controller) listenForErrors() {
@@ -196,6 +358,16 @@ func (c *controller) debug(msg string) {
 	}
 }
 
+// audit records something done in the console. Audit lines go to
+// debug.log whether or not debugging is on, so they outlive the session.
+func (c *controller) audit(msg string) {
+	msg = fmt.Sprintf("AUDIT: %s", msg)
+	c.debug(msg)
+	if !c.debugToFile {
+		c.appendDebugFile(fmt.Sprintf("> [%v] %s", time.Now().Local(), msg))
+	}
+}
+
 func (c *controller) appendDebugFile(msg string) {
 	f, err := os.OpenFile("debug.log",
 		os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
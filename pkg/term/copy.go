@@ -0,0 +1,184 @@
+package term
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	ui "github.com/gizak/termui/v3"
+	"github.com/gizak/termui/v3/widgets"
+	corev1 "k8s.io/api/core/v1"
+)
+
+const (
+	copyUpload   = "Upload to container"
+	copyDownload = "Download from container"
+
+	copyTitle = " Copy "
+	copyHelp  = "<esc> cancel"
+)
+
+var copyChoices = []string{copyUpload, copyDownload}
+
+// chooseContainer asks which container of a pod to use when it has more
+// than one
+func (c *controller) chooseContainer(pod *corev1.Pod, title string) (container string) {
+	if len(pod.Spec.Containers) == 1 {
+		return pod.Spec.Containers[0].Name
+	}
+	containerNames := make([]string, 0)
+	for _, cont := range pod.Spec.Containers {
+		containerNames = append(containerNames, cont.Name)
+	}
+	container = c.choicePrompt(title, containerNames)
+	if container != _quit && container != _cancel {
+		c.renderDefaults()
+	}
+	return
+}
+
+// copyFiles uploads a local file or directory into the selected pod, or
+// downloads one from it, the way kubectl cp does. The container needs a
+// tar binary.
+func (c *controller) copyFiles() (q string) {
+	ns, currentPod := c.getSelectedPod()
+	if currentPod == "" {
+		return
+	}
+	pod, err := c.factory.GetPod(ns, currentPod)
+	if err != nil {
+		c.errorChan <- newErrorWithStack(err)
+		return
+	}
+	direction := c.choicePrompt(fmt.Sprintf(" Copy files with %s ", currentPod), copyChoices)
+	if direction == _quit || direction == _cancel {
+		return direction
+	}
+	container := c.chooseContainer(pod, " Which container to copy with? ")
+	if container == _quit || container == _cancel {
+		return container
+	}
+
+	srcTitle, destTitle, destInitial := " Local file or directory to upload ", " Container directory to upload into ", "/tmp"
+	if direction == copyDownload {
+		srcTitle, destTitle, destInitial = " Container file or directory to download ", " Local directory to download into ", "."
+	}
+	src := c.inputPrompt(srcTitle, "", nil)
+	if src == _quit || src == _cancel || src == "" {
+		return src
+	}
+	dest := c.inputPrompt(destTitle, destInitial, nil)
+	if dest == _quit || dest == _cancel || dest == "" {
+		return dest
+	}
+
+	// the half of the copy that is local can be given relative to home
+	if direction == copyUpload {
+		src = expandHome(src)
+	} else {
+		dest = expandHome(dest)
+	}
+
+	var done, total int64 = 0, -1
+	progress := func(d, t int64) {
+		atomic.StoreInt64(&done, d)
+		atomic.StoreInt64(&total, t)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	result := make(chan error, 1)
+	var skipped []string
+	go func() {
+		var err error
+		if direction == copyUpload {
+			err = c.factory.CopyToPod(ns, currentPod, container, src, dest, progress, ctx)
+		} else {
+			skipped, err = c.factory.CopyFromPod(ns, currentPod, container, src, dest, progress, ctx)
+		}
+		result <- err
+	}()
+
+	name := fmt.Sprintf("%s to %s/%s:%s", src, currentPod, container, dest)
+	if direction == copyDownload {
+		name = fmt.Sprintf("%s/%s:%s to %s", currentPod, container, src, dest)
+	}
+	c.debug(fmt.Sprintf("Copying %s", name))
+	if err = c.followCopy(name, &done, &total, result, cancel); err == context.Canceled {
+		c.debug(fmt.Sprintf("Cancelled copying %s", name))
+		return
+	} else if err != nil {
+		c.debug(fmt.Sprintf("Failed to copy %s: %v", name, err))
+		c.errorChan <- newErrorWithStack(err)
+		return
+	}
+	c.debug(fmt.Sprintf("Copied %s", name))
+	if len(skipped) > 0 {
+		c.debug(fmt.Sprintf("Skipped %d links and special files copying to %s: %s", len(skipped), dest, strings.Join(skipped, ", ")))
+		c.errorChan <- newErrorWithStack(fmt.Errorf("Skipped links and special files copying to %s: %s", dest, strings.Join(skipped, ", ")))
+	}
+	return
+}
+
+// followCopy shows the progress of a copy in a popup until it finishes or
+// the user cancels it
+func (c *controller) followCopy(name string, done, total *int64, result <-chan error, cancel func()) error {
+	ui.Clear()
+	c.renderView()
+	pane := widgets.NewParagraph()
+	pane.Title = copyTitle
+	pane.WrapText = true
+	pane.TextStyle = ui.NewStyle(ui.ColorCyan)
+	x, y := ui.TerminalDimensions()
+	pane.SetRect(x/4, y/2-4, (x - x/4), y/2+4)
+
+	ticker := time.NewTicker(time.Duration(200) * time.Millisecond)
+	defer ticker.Stop()
+	for {
+		pane.Text = fmt.Sprintf("Copying %s\n\n%s\n\n%s", name, copyProgress(atomic.LoadInt64(done), atomic.LoadInt64(total)), copyHelp)
+		c.mux.Lock()
+		ui.Render(pane)
+		c.mux.Unlock()
+
+		select {
+		case err := <-result:
+			return err
+		case <-ticker.C:
+		case e := <-c.events:
+			switch e.ID {
+			// the copy finishes in the background, the result is buffered
+			// for it
+			case "<Escape>", ctrlC:
+				cancel()
+				return context.Canceled
+			}
+		}
+	}
+}
+
+// copyProgress describes how far a copy has got, with a percentage when the
+// size is known up front
+func copyProgress(done, total int64) string {
+	if total <= 0 {
+		return byteCount(done)
+	}
+	// the tar headers take the count past the size of the files
+	percent := done * 100 / total
+	if percent > 100 {
+		percent = 100
+	}
+	return fmt.Sprintf("%s of %s (%d%%)", byteCount(done), byteCount(total), percent)
+}
+
+// expandHome expands a leading ~/ into the home directory
+func expandHome(path string) string {
+	if strings.HasPrefix(path, "~/") {
+		if home, err := os.UserHomeDir(); err == nil {
+			return filepath.Join(home, path[2:])
+		}
+	}
+	return path
+}
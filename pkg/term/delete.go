@@ -0,0 +1,76 @@
+package term
+
+import (
+	"fmt"
+	"strconv"
+	"unicode"
+)
+
+const (
+	deleteDefault = "Delete"
+	deleteGrace   = "Delete with grace period..."
+	deleteForce   = "Force delete (no grace period)"
+	deleteEvict   = "Evict (honors PodDisruptionBudgets)"
+)
+
+var deleteChoices = []string{deleteDefault, deleteGrace, deleteForce, deleteEvict}
+
+// deletePod deletes or evicts the selected pod, depending on the option
+// chosen, once confirmed
+func (c *controller) deletePod() (q string) {
+	ns, pod := c.getSelectedPod()
+	if pod == "" {
+		return
+	}
+	choice := c.choicePrompt(fmt.Sprintf(" Remove %s how? ", pod), deleteChoices)
+	if choice == _quit || choice == _cancel {
+		return choice
+	}
+
+	var gracePeriod *int64
+	var question, status string
+	switch choice {
+	case deleteDefault:
+		question = fmt.Sprintf("Delete pod %s?", pod)
+	case deleteGrace:
+		input := c.inputPrompt(" Grace period in seconds ", "30", unicode.IsDigit)
+		if input == _quit || input == _cancel {
+			return input
+		}
+		seconds, err := strconv.ParseInt(input, 10, 64)
+		if err != nil {
+			c.errorChan <- newErrorWithStack(err)
+			return
+		}
+		gracePeriod = &seconds
+		question = fmt.Sprintf("Delete pod %s with a %ds grace period?", pod, seconds)
+	case deleteForce:
+		var zero int64
+		gracePeriod = &zero
+		question = fmt.Sprintf("Force delete pod %s without waiting for it to stop?", pod)
+	case deleteEvict:
+		question = fmt.Sprintf("Evict pod %s?", pod)
+	}
+	if q = c.confirmPrompt(question); q != confirmYes {
+		return
+	}
+
+	var err error
+	if choice == deleteEvict {
+		c.debug(fmt.Sprintf("Evicting pod %s", pod))
+		err = c.factory.EvictPod(ns, pod)
+		status = "Evicting"
+	} else {
+		c.debug(fmt.Sprintf("Deleting pod %s", pod))
+		err = c.factory.DeletePod(ns, pod, gracePeriod)
+		status = "Terminating"
+	}
+	if err != nil {
+		c.debug(fmt.Sprintf("Failed to remove pod %s: %v", pod, err))
+		c.errorChan <- newErrorWithStack(err)
+		return ""
+	}
+	c.debug(fmt.Sprintf("Removed pod %s", pod))
+	c.setPodStatus(ns, pod, status)
+	return ""
+}
@@ -0,0 +1,218 @@
+package term
+
+import (
+	"fmt"
+
+	ui "github.com/gizak/termui/v3"
+)
+
+const (
+	diffContext   = 3
+	diffIdentical = "No differences"
+
+	// how far the diff searches for a shortest edit script before showing
+	// what is left as removed and added wholesale, which keeps documents
+	// with nothing in common from taking seconds
+	diffMaxSearch = 1024
+)
+
+type diffOp int
+
+const (
+	diffEqual diffOp = iota
+	diffRemove
+	diffAdd
+)
+
+type diffLine struct {
+	op   diffOp
+	text string
+}
+
+// diffRows renders a line diff of two documents for a styledList. Removed
+// lines are red and prefixed with "-", added lines are green and prefixed
+// with "+", and only a few unchanged lines are kept around each change.
+func diffRows(before, after []string) [][]span {
+	lines := diffLines(before, after)
+
+	// mark the unchanged lines close enough to a change to keep
+	keep := make([]bool, len(lines))
+	changed := false
+	for idx, line := range lines {
+		if line.op == diffEqual {
+			continue
+		}
+		changed = true
+		for k := idx - diffContext; k <= idx+diffContext; k++ {
+			if k >= 0 && k < len(lines) {
+				keep[k] = true
+			}
+		}
+	}
+	if !changed {
+		return [][]span{{{text: diffIdentical, style: ui.NewStyle(ui.ColorCyan)}}}
+	}
+
+	rows := make([][]span, 0)
+	var beforeLine, afterLine int
+	skipping := true
+	for idx, line := range lines {
+		if keep[idx] && skipping {
+			rows = append(rows, []span{{
+				text:  fmt.Sprintf("@@ -%d +%d @@", beforeLine+1, afterLine+1),
+				style: ui.NewStyle(ui.ColorCyan),
+			}})
+		}
+		skipping = !keep[idx]
+		if keep[idx] {
+			switch line.op {
+			case diffEqual:
+				rows = append(rows, []span{{text: "  " + line.text, style: ui.NewStyle(ui.ColorWhite)}})
+			case diffRemove:
+				rows = append(rows, []span{{text: "- " + line.text, style: ui.NewStyle(ui.ColorRed)}})
+			case diffAdd:
+				rows = append(rows, []span{{text: "+ " + line.text, style: ui.NewStyle(ui.ColorGreen)}})
+			}
+		}
+		if line.op != diffAdd {
+			beforeLine++
+		}
+		if line.op != diffRemove {
+			afterLine++
+		}
+	}
+	return rows
+}
+
+// diffLines produces the edits that turn one set of lines into the other
+// with Myers' diff in linear space, so that large manifests don't need a
+// table of every pair of lines
+func diffLines(a, b []string) []diffLine {
+	return appendDiff(make([]diffLine, 0, len(a)+len(b)), a, b)
+}
+
+// appendDiff trims the lines a and b start and end with, which are
+// unchanged, and splits what is left where the middle of a shortest edit
+// script crosses it until one side is empty
+func appendDiff(lines []diffLine, a, b []string) []diffLine {
+	prefix := 0
+	for prefix < len(a) && prefix < len(b) && a[prefix] == b[prefix] {
+		lines = append(lines, diffLine{op: diffEqual, text: a[prefix]})
+		prefix++
+	}
+	a, b = a[prefix:], b[prefix:]
+	suffix := 0
+	for suffix < len(a) && suffix < len(b) && a[len(a)-1-suffix] == b[len(b)-1-suffix] {
+		suffix++
+	}
+	tail := a[len(a)-suffix:]
+	a, b = a[:len(a)-suffix], b[:len(b)-suffix]
+
+	x, y := middleSnake(a, b)
+	if len(a) == 0 || len(b) == 0 || (x == 0 && y == 0) || (x == len(a) && y == len(b)) {
+		for _, line := range a {
+			lines = append(lines, diffLine{op: diffRemove, text: line})
+		}
+		for _, line := range b {
+			lines = append(lines, diffLine{op: diffAdd, text: line})
+		}
+	} else {
+		lines = appendDiff(lines, a[:x], b[:y])
+		lines = appendDiff(lines, a[x:], b[y:])
+	}
+
+	for _, line := range tail {
+		lines = append(lines, diffLine{op: diffEqual, text: line})
+	}
+	return lines
+}
+
+// middleSnake searches for a shortest edit script from both ends of a and
+// b at once, returning where the two searches meet, or 0, 0 when they
+// don't within diffMaxSearch edits. It only keeps the furthest point
+// reached on each diagonal, not every point.
+func middleSnake(a, b []string) (x, y int) {
+	n, m := len(a), len(b)
+	if n == 0 || m == 0 {
+		return 0, 0
+	}
+	maxD := (n + m + 1) / 2
+	if maxD > diffMaxSearch {
+		maxD = diffMaxSearch
+	}
+	offset := maxD
+	// forward[offset+k] is how far along a the forward search got on
+	// diagonal k, backward[offset+k] the same counting from the ends
+	forward := make([]int, 2*maxD+2)
+	backward := make([]int, 2*maxD+2)
+	for idx := range forward {
+		forward[idx], backward[idx] = -1, -1
+	}
+	forward[offset+1], backward[offset+1] = 0, 0
+	delta := n - m
+	// when the lengths differ by an odd number the searches meet going
+	// forwards, otherwise going backwards
+	odd := delta%2 != 0
+	// diagonals that ran off the edges aren't searched again
+	var fStart, fEnd, bStart, bEnd int
+
+	for d := 0; d < maxD; d++ {
+		for k := -d + fStart; k <= d-fEnd; k += 2 {
+			var fx int
+			if k == -d || (k != d && forward[offset+k-1] < forward[offset+k+1]) {
+				fx = forward[offset+k+1]
+			} else {
+				fx = forward[offset+k-1] + 1
+			}
+			fy := fx - k
+			for fx < n && fy < m && a[fx] == b[fy] {
+				fx++
+				fy++
+			}
+			forward[offset+k] = fx
+			switch {
+			case fx > n:
+				fEnd += 2
+			case fy > m:
+				fStart += 2
+			case odd:
+				if bk := offset + delta - k; bk >= 0 && bk < len(backward) && backward[bk] != -1 {
+					if fx >= n-backward[bk] {
+						return fx, fy
+					}
+				}
+			}
+		}
+
+		for k := -d + bStart; k <= d-bEnd; k += 2 {
+			var bx int
+			if k == -d || (k != d && backward[offset+k-1] < backward[offset+k+1]) {
+				bx = backward[offset+k+1]
+			} else {
+				bx = backward[offset+k-1] + 1
+			}
+			by := bx - k
+			for bx < n && by < m && a[n-bx-1] == b[m-by-1] {
+				bx++
+				by++
+			}
+			backward[offset+k] = bx
+			switch {
+			case bx > n:
+				bEnd += 2
+			case by > m:
+				bStart += 2
+			case !odd:
+				if fk := offset + delta - k; fk >= 0 && fk < len(forward) && forward[fk] != -1 {
+					fx := forward[fk]
+					fy := offset + fx - fk
+					if fx >= n-bx {
+						return fx, fy
+					}
+				}
+			}
+		}
+	}
+	// nothing in common, or too far apart to be worth finding out
+	return 0, 0
+}
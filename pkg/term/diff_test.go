@@ -0,0 +1,97 @@
+package term
+
+import (
+	"fmt"
+	"math/rand"
+	"strings"
+	"testing"
+)
+
+// applyDiff returns the documents on either side of a diff
+func applyDiff(lines []diffLine) (before, after []string) {
+	before, after = make([]string, 0), make([]string, 0)
+	for _, line := range lines {
+		if line.op != diffAdd {
+			before = append(before, line.text)
+		}
+		if line.op != diffRemove {
+			after = append(after, line.text)
+		}
+	}
+	return
+}
+
+func countEdits(lines []diffLine) (edits int) {
+	for _, line := range lines {
+		if line.op != diffEqual {
+			edits++
+		}
+	}
+	return
+}
+
+func TestDiffLines(t *testing.T) {
+	for _, tc := range []struct {
+		before, after string
+		edits         int
+	}{
+		{"", "", 0},
+		{"a b c", "a b c", 0},
+		{"", "a b", 2},
+		{"a b", "", 2},
+		{"a b c", "a c", 1},
+		{"a c", "a b c", 1},
+		{"a b c a b b a", "c b a b a c", 5},
+		{"x y z", "a b c", 6},
+	} {
+		before, after := strings.Fields(tc.before), strings.Fields(tc.after)
+		lines := diffLines(before, after)
+		gotBefore, gotAfter := applyDiff(lines)
+		if strings.Join(gotBefore, " ") != tc.before || strings.Join(gotAfter, " ") != tc.after {
+			t.Errorf("diff of %q and %q gives %q and %q", tc.before, tc.after, gotBefore, gotAfter)
+		}
+		if edits := countEdits(lines); edits != tc.edits {
+			t.Errorf("diff of %q and %q has %d edits, expected %d", tc.before, tc.after, edits, tc.edits)
+		}
+	}
+}
+
+func TestDiffLinesRandom(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	doc := func(n int) []string {
+		lines := make([]string, r.Intn(n))
+		for idx := range lines {
+			lines[idx] = string(rune('a' + r.Intn(4)))
+		}
+		return lines
+	}
+	for i := 0; i < 10000; i++ {
+		before, after := doc(20), doc(20)
+		gotBefore, gotAfter := applyDiff(diffLines(before, after))
+		if fmt.Sprint(gotBefore) != fmt.Sprint(before) || fmt.Sprint(gotAfter) != fmt.Sprint(after) {
+			t.Fatalf("diff of %q and %q gives %q and %q", before, after, gotBefore, gotAfter)
+		}
+	}
+}
+
+func TestDiffLinesLarge(t *testing.T) {
+	doc := func(prefix string) []string {
+		lines := make([]string, 10000)
+		for idx := range lines {
+			lines[idx] = fmt.Sprintf("%s %d", prefix, idx)
+		}
+		return lines
+	}
+	before, after := doc("before"), doc("after")
+	if edits := countEdits(diffLines(before, after)); edits != 20000 {
+		t.Errorf("expected every line to change, got %d edits", edits)
+	}
+
+	after = append([]string(nil), before...)
+	for idx := 0; idx < len(after); idx += 100 {
+		after[idx] = "changed"
+	}
+	if edits := countEdits(diffLines(before, after)); edits != 200 {
+		t.Errorf("expected 200 edits, got %d", edits)
+	}
+}
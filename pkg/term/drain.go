@@ -0,0 +1,181 @@
+package term
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+	"unicode"
+
+	"github.com/tinyzimmer/kubeconsole/pkg/k8sutils"
+)
+
+const (
+	drainKeepEmptyDir   = "Drain"
+	drainDeleteEmptyDir = "Drain, deleting emptyDir data"
+	drainForce          = "Drain, evicting pods no controller manages"
+	drainForceDeleting  = "Drain, evicting pods no controller manages and deleting emptyDir data"
+	drainDefaultTimeout = "300"
+	// a day is longer than any drain should be left to run
+	drainMaxTimeout = 24 * 60 * 60
+)
+
+var drainChoices = []string{drainKeepEmptyDir, drainDeleteEmptyDir, drainForce, drainForceDeleting}
+
+// cordonNode toggles whether the selected node accepts new pods once
+// confirmed
+func (c *controller) cordonNode() (q string) {
+	node := c.getSelectedNode()
+	if node == nil {
+		return
+	}
+	action := "Cordon"
+	if node.Unschedulable {
+		action = "Uncordon"
+	}
+	if q = c.confirmPrompt(fmt.Sprintf("%s node %s?", action, node.Name)); q != confirmYes {
+		return
+	}
+	c.debug(fmt.Sprintf("%sing node %s", action, node.Name))
+	if err := c.factory.CordonNode(node.Name, !node.Unschedulable); err != nil {
+		c.debug(fmt.Sprintf("Failed to %s node %s: %v", strings.ToLower(action), node.Name, err))
+		c.errorChan <- newErrorWithStack(err)
+		return ""
+	}
+	c.debug(fmt.Sprintf("%sed node %s", action, node.Name))
+	c.nodeList = c.newNodeList()
+	return ""
+}
+
+// drainNode cordons the selected node and evicts its pods once the options
+// are chosen and confirmed. Progress is followed in the details pane while
+// the drain runs in the background. Asking to drain while a drain is
+// running offers to stop it instead.
+func (c *controller) drainNode() (q string) {
+	if c.drainCancel != nil {
+		if q = c.confirmPrompt("A drain is running, stop it?"); q == confirmYes {
+			c.drainCancel()
+			return ""
+		}
+		return
+	}
+	node := c.getSelectedNode()
+	if node == nil {
+		return
+	}
+	choice := c.choicePrompt(fmt.Sprintf(" Drain %s how? ", node.Name), drainChoices)
+	if choice == _quit || choice == _cancel {
+		return choice
+	}
+	seconds, q := c.drainTimeoutPrompt()
+	if q != "" {
+		return q
+	}
+	opts := k8sutils.DrainOptions{
+		DeleteEmptyDirData: choice == drainDeleteEmptyDir || choice == drainForceDeleting,
+		Force:              choice == drainForce || choice == drainForceDeleting,
+		Timeout:            time.Duration(seconds) * time.Second,
+	}
+	question := fmt.Sprintf("Cordon %s and evict its pods?", node.Name)
+	switch {
+	case opts.Force && opts.DeleteEmptyDirData:
+		question = fmt.Sprintf("Cordon %s and evict its pods, including unmanaged ones that won't be recreated, deleting their emptyDir data?", node.Name)
+	case opts.Force:
+		question = fmt.Sprintf("Cordon %s and evict its pods, including unmanaged ones that won't be recreated?", node.Name)
+	case opts.DeleteEmptyDirData:
+		question = fmt.Sprintf("Cordon %s and evict its pods, deleting their emptyDir data?", node.Name)
+	}
+	if q = c.confirmPrompt(question); q != confirmYes {
+		return
+	}
+
+	c.debug(fmt.Sprintf("Draining node %s", node.Name))
+	ctx, cancel := context.WithCancel(context.Background())
+	updates, err := c.factory.DrainNode(node.Name, opts, ctx)
+	if err != nil {
+		cancel()
+		c.debug(fmt.Sprintf("Failed to drain node %s: %v", node.Name, err))
+		c.errorChan <- newErrorWithStack(err)
+		return ""
+	}
+	c.drainCancel = cancel
+	go c.followDrain(node.Name, updates)
+	// the node has been cordoned
+	c.nodeList = c.newNodeList()
+	return ""
+}
+
+// followDrain shows the status of every pod on a node being drained until
+// the drain finishes
+func (c *controller) followDrain(node string, updates <-chan k8sutils.DrainProgress) {
+	order := make([]string, 0)
+	latest := make(map[string]k8sutils.DrainProgress)
+	for update := range updates {
+		key := fmt.Sprintf("%s/%s", update.Namespace, update.Pod)
+		if _, ok := latest[key]; !ok {
+			order = append(order, key)
+		}
+		latest[key] = update
+		if update.Err != nil {
+			c.debug(fmt.Sprintf("Drain %s: %s %s: %v", node, key, update.Status, update.Err))
+		} else {
+			c.debug(fmt.Sprintf("Drain %s: %s %s", node, key, update.Status))
+		}
+		c.nodeDetailsChan <- drainReport(node, order, latest, false)
+	}
+	cancelIfNotNil(c.drainCancel)
+	c.drainCancel = nil
+	c.debug(fmt.Sprintf("Finished draining node %s", node))
+	c.nodeDetailsChan <- drainReport(node, order, latest, true)
+}
+
+// drainReport formats the progress of a drain for the details pane
+func drainReport(node string, order []string, latest map[string]k8sutils.DrainProgress, finished bool) string {
+	var done, evicted int
+	for _, key := range order {
+		if latest[key].Done() {
+			done++
+		}
+		if latest[key].Status == k8sutils.DrainEvicted {
+			evicted++
+		}
+	}
+	var buf bytes.Buffer
+	if finished {
+		fmt.Fprintf(&buf, "Finished draining %s: %d of %d pods evicted\n\n", node, evicted, len(order))
+	} else {
+		fmt.Fprintf(&buf, "Draining %s: %d of %d pods done\n\n", node, done, len(order))
+	}
+	w := tabwriter.NewWriter(&buf, 0, 8, 2, ' ', 0)
+	fmt.Fprintln(w, "  POD\tSTATUS\tERROR")
+	for _, key := range order {
+		update := latest[key]
+		var errMsg string
+		if update.Err != nil {
+			errMsg = update.Err.Error()
+		}
+		fmt.Fprintf(w, "  %s\t%s\t%s\n", key, update.Status, errMsg)
+	}
+	w.Flush()
+	return buf.String()
+}
+
+// drainTimeoutPrompt asks how long a drain may take, asking again until
+// it is given a number of seconds between 1 and drainMaxTimeout
+func (c *controller) drainTimeoutPrompt() (seconds int64, q string) {
+	title := " Give up after how many seconds? "
+	for {
+		input := c.inputPrompt(title, drainDefaultTimeout, unicode.IsDigit)
+		if input == _quit || input == _cancel {
+			return 0, input
+		}
+		seconds, err := strconv.ParseInt(input, 10, 64)
+		if err == nil && seconds >= 1 && seconds <= drainMaxTimeout {
+			return seconds, ""
+		}
+		title = fmt.Sprintf(" Give up after how many seconds? Between 1 and %d ", drainMaxTimeout)
+	}
+}
@@ -0,0 +1,120 @@
+package term
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/tinyzimmer/kubeconsole/pkg/k8sutils"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"sigs.k8s.io/yaml"
+)
+
+const editHeader = `# Please edit the object below. Lines beginning with a '#' will be ignored,
+# and an empty file will abort the edit. If an error occurs while saving this file will be
+# reopened with the relevant failures.
+#
+`
+
+// editObject opens the live copy of an object in $EDITOR and saves the
+// result back as an update. Like kubectl edit, a failed save reopens the
+// editor on what was written with the error at the top, until it saves,
+// the file is emptied, or it is closed without changes. Secrets aren't
+// edited, that would write their values out unmasked and unaudited.
+func (c *controller) editObject(resource k8sutils.APIResource, ns, name string) (*unstructured.Unstructured, error) {
+	if resource.Group == "" && resource.Resource == "secrets" {
+		return nil, fmt.Errorf("Secrets can't be edited here, their values are only revealed in the secrets view")
+	}
+	live, err := c.factory.GetObject(resource, ns, name)
+	if err != nil {
+		return nil, err
+	}
+	unstructured.RemoveNestedField(live.Object, "metadata", "managedFields")
+	content, err := yaml.Marshal(live.Object)
+	if err != nil {
+		return nil, err
+	}
+
+	var failure error
+	for {
+		header := editHeader
+		if failure != nil {
+			header = fmt.Sprintf("%s# %s %q was not valid:\n%s#\n", header, live.GetKind(), name, commentLines(failure.Error()))
+		}
+		edited, err := c.runEditor(fmt.Sprintf("%s.yaml", name), append([]byte(header), content...))
+		if err != nil {
+			return nil, err
+		}
+		edited = stripHeaderComments(edited)
+		if len(bytes.TrimSpace(edited)) == 0 {
+			c.debug(fmt.Sprintf("Edit of %s %s aborted, the file was emptied", resource, name))
+			return nil, nil
+		}
+		if bytes.Equal(bytes.TrimSpace(edited), bytes.TrimSpace(content)) {
+			if failure != nil {
+				return nil, failure
+			}
+			c.debug(fmt.Sprintf("Edit of %s %s cancelled, no changes made", resource, name))
+			return nil, nil
+		}
+		content = edited
+
+		update, err := parseEdit(live, edited)
+		if err != nil {
+			failure = err
+			continue
+		}
+		c.debug(fmt.Sprintf("Updating %s %s at resourceVersion %s", resource, name, live.GetResourceVersion()))
+		updated, err := c.factory.UpdateObject(resource, update)
+		if apierrors.IsConflict(err) {
+			// editing again can't fix a stale resourceVersion
+			return nil, fmt.Errorf("%s %s was changed by someone else while it was being edited, edit it again to pick up their changes", live.GetKind(), name)
+		} else if err != nil {
+			c.debug(fmt.Sprintf("Failed to update %s %s: %v", resource, name, err))
+			failure = err
+			continue
+		}
+		c.debug(fmt.Sprintf("Updated %s %s", resource, name))
+		return updated, nil
+	}
+}
+
+// parseEdit turns an edited document back into an object, refusing edits
+// that would make it a different object
+func parseEdit(live *unstructured.Unstructured, edited []byte) (*unstructured.Unstructured, error) {
+	data, err := yaml.YAMLToJSON(edited)
+	if err != nil {
+		return nil, err
+	}
+	update := &unstructured.Unstructured{}
+	if err := update.UnmarshalJSON(data); err != nil {
+		return nil, err
+	}
+	if update.GetAPIVersion() != live.GetAPIVersion() ||
+		update.GetKind() != live.GetKind() ||
+		update.GetName() != live.GetName() ||
+		update.GetNamespace() != live.GetNamespace() {
+		return nil, errors.New("apiVersion, kind, name and namespace can't be changed")
+	}
+	return update, nil
+}
+
+// stripHeaderComments drops the comment lines at the top of an edited file
+func stripHeaderComments(edited []byte) []byte {
+	lines := strings.SplitAfter(string(edited), "\n")
+	for len(lines) > 0 && strings.HasPrefix(lines[0], "#") {
+		lines = lines[1:]
+	}
+	return []byte(strings.Join(lines, ""))
+}
+
+// commentLines formats an error as a YAML comment, one bullet per line
+func commentLines(msg string) string {
+	var buf strings.Builder
+	for _, line := range strings.Split(strings.TrimSpace(msg), "\n") {
+		buf.WriteString(fmt.Sprintf("# * %s\n", line))
+	}
+	return buf.String()
+}
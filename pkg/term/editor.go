@@ -0,0 +1,66 @@
+package term
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	ui "github.com/gizak/termui/v3"
+)
+
+const defaultEditor = "vi"
+
+// errLostTerminal is returned when the terminal can't be taken back from
+// the editor, and the views should quit
+var errLostTerminal = errors.New("lost the terminal")
+
+// runEditor hands the terminal over to $EDITOR to edit content, and
+// returns what was saved. The name is used as the suffix of the temp file so
+// that editors can pick up the file type. termui is shut down while the
+// editor runs, and nothing else is allowed to render until it is back. If
+// termui can't be started again the session is over, and errLostTerminal
+// is returned.
+func (c *controller) runEditor(name string, content []byte) ([]byte, error) {
+	f, err := ioutil.TempFile("", fmt.Sprintf("kubeconsole-*-%s", filepath.Base(name)))
+	if err != nil {
+		return nil, err
+	}
+	defer os.Remove(f.Name())
+	if _, err = f.Write(content); err != nil {
+		f.Close()
+		return nil, err
+	}
+	if err = f.Close(); err != nil {
+		return nil, err
+	}
+
+	editor := strings.Fields(os.Getenv("EDITOR"))
+	if len(editor) == 0 {
+		editor = []string{defaultEditor}
+	}
+	cmd := exec.Command(editor[0], append(editor[1:], f.Name())...)
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+
+	c.debug(fmt.Sprintf("Opening %s in %s", name, editor[0]))
+	c.mux.Lock()
+	ui.Close()
+	runErr := cmd.Run()
+	initErr := ui.Init()
+	c.mux.Unlock()
+	if initErr != nil {
+		// there is no terminal left to show anything on, so Run returns
+		// this once the views have quit
+		c.fatalErr = fmt.Errorf("Couldn't take the terminal back from %s: %v", editor[0], initErr)
+		return nil, errLostTerminal
+	}
+	if runErr != nil {
+		return nil, fmt.Errorf("%s exited with an error: %v", editor[0], runErr)
+	}
+	return ioutil.ReadFile(f.Name())
+}
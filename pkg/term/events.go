@@ -0,0 +1,185 @@
+package term
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"text/tabwriter"
+	"time"
+
+	ui "github.com/gizak/termui/v3"
+	"github.com/gizak/termui/v3/widgets"
+	"github.com/tinyzimmer/kubeconsole/pkg/k8sutils"
+)
+
+const (
+	eventsTitle       = " Events "
+	eventsLoading     = "Loading events..."
+	eventsNoNS        = "Select a namespace first"
+	eventMessageTitle = " Message "
+)
+
+var eventColumns = []string{"LAST SEEN", "TYPE", "REASON", "OBJECT", "COUNT", "MESSAGE"}
+
+func (c *controller) newEventList() (t *selectableTable) {
+	t = newSelectableTable(eventColumns...)
+	t.Title = fmt.Sprintf(" %s   Namespace: %s ", eventsTitle, c.currentNamespace)
+	t.TextStyle = ui.NewStyle(ui.ColorCyan)
+	x, y := ui.TerminalDimensions()
+	t.SetRect(0, 3, x, y-9)
+
+	cancelIfNotNil(c.eventWatchCancel)
+	c.eventSummaries = nil
+	if c.currentNamespace == "" {
+		t.Data = [][]string{{eventsNoNS}}
+		return
+	}
+	t.Data = [][]string{{eventsLoading}}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	c.eventWatchCancel = cancel
+	go func() {
+		updates, err := c.factory.WatchEvents(c.currentNamespace, ctx)
+		if err != nil {
+			cancel()
+			c.errorChan <- newErrorWithStack(err)
+			return
+		}
+		c.syncEventList(t, updates)
+	}()
+	return
+}
+
+func newEventMessageWindow() *widgets.Paragraph {
+	pane := widgets.NewParagraph()
+	pane.Title = eventMessageTitle
+	pane.WrapText = true
+	x, y := ui.TerminalDimensions()
+	pane.SetRect(0, y-9, x, y-3)
+	return pane
+}
+
+// syncEventList replaces the rows of the events table every time the
+// watch sends an update. When the cursor is on the newest event it follows
+// new events as they arrive.
+func (c *controller) syncEventList(t *selectableTable, updates <-chan []k8sutils.EventSummary) {
+	ticker := time.NewTicker(ageRefresh)
+	defer ticker.Stop()
+	for {
+		select {
+		case update, ok := <-updates:
+			if !ok {
+				c.debug("Event watch closed")
+				return
+			}
+			following := len(c.eventSummaries) == 0 || t.SelectedRow >= len(c.eventSummaries)-1
+			c.eventSummaries = update
+			t.Data, t.DataStyles = eventRows(update)
+			if following {
+				t.ScrollBottom()
+			}
+		case <-ticker.C:
+			t.Data, t.DataStyles = eventRows(c.eventSummaries)
+		}
+
+		if t == c.eventList && c.navWindow.ActiveTabIndex == eventsTab {
+			c.mux.Lock()
+			ui.Render(t)
+			c.mux.Unlock()
+		}
+	}
+}
+
+// eventRows formats events into table rows, highlighting warnings
+func eventRows(events []k8sutils.EventSummary) (rows [][]string, styles map[int]ui.Style) {
+	rows = make([][]string, 0)
+	styles = make(map[int]ui.Style)
+	for idx, ev := range events {
+		rows = append(rows, []string{
+			age(ev.LastSeen),
+			ev.Type,
+			ev.Reason,
+			ev.Object,
+			fmt.Sprintf("%d", ev.Count),
+			ev.Message,
+		})
+		if ev.Warning() {
+			styles[idx] = ui.NewStyle(ui.ColorRed)
+		}
+	}
+	return
+}
+
+// eventsSection formats events the way `kubectl describe` lists them
+// under an object
+func eventsSection(events []k8sutils.EventSummary) string {
+	if len(events) == 0 {
+		return "Events:        <none>\n"
+	}
+	var buf bytes.Buffer
+	buf.WriteString("Events:\n")
+	w := tabwriter.NewWriter(&buf, 0, 8, 2, ' ', 0)
+	fmt.Fprintln(w, "  Type\tReason\tAge\tFrom\tMessage")
+	fmt.Fprintln(w, "  ----\t------\t---\t----\t-------")
+	for _, ev := range events {
+		seen := age(ev.LastSeen)
+		if ev.Count > 1 {
+			seen = fmt.Sprintf("%s (x%d over %s)", seen, ev.Count, age(ev.FirstSeen))
+		}
+		fmt.Fprintf(w, "  %s\t%s\t%s\t%s\t%s\n", ev.Type, ev.Reason, seen, ev.Source, ev.Message)
+	}
+	w.Flush()
+	return buf.String()
+}
+
+// renderEvents renders the panes of the events view
+func (c *controller) renderEvents() {
+	c.mux.Lock()
+	defer c.mux.Unlock()
+	if ev := c.getSelectedEvent(); ev != nil {
+		c.eventMessage.Text = fmt.Sprintf("%s  %s: %s", ev.Object, ev.Reason, ev.Message)
+	} else {
+		c.eventMessage.Text = ""
+	}
+	ui.Render(
+		c.navWindow,
+		c.serverWindow,
+		c.helpWindow,
+		c.eventList,
+		c.eventMessage,
+	)
+}
+
+func (c *controller) getSelectedEvent() *k8sutils.EventSummary {
+	if c.eventList == nil || c.eventList.SelectedRow >= len(c.eventSummaries) {
+		return nil
+	}
+	return &c.eventSummaries[c.eventList.SelectedRow]
+}
+
+func (c *controller) pollEvents() (next int) {
+	c.focusTab(eventsTab)
+	c.helpWindow.Text = eventsHelpText
+	c.eventList = c.newEventList()
+	for {
+		ui.Clear()
+		c.renderEvents()
+		e := <-c.events
+		switch e.ID {
+
+		// restart the watch
+		case "r":
+			c.eventList = c.newEventList()
+
+		default:
+			if next, ok := c.switchView(e.ID); ok {
+				cancelIfNotNil(c.eventWatchCancel)
+				return next
+			}
+			if q := c.checkCommon(c.eventList, e.ID); q == _quit {
+				cancelIfNotNil(c.eventWatchCancel)
+				return quitView
+			}
+		}
+	}
+}
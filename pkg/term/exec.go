@@ -1,108 +1,153 @@
 package term
 
 import (
-	"bytes"
-	"context"
 	"fmt"
 	"io"
-	"io/ioutil"
-	"regexp"
+	"os"
+	"os/signal"
 	"strings"
+	"syscall"
 	"time"
 
-	ui "github.com/gizak/termui/v3"
-	"github.com/google/go-cmp/cmp"
-	"golang.org/x/text/encoding/unicode"
-	"golang.org/x/text/transform"
+	"github.com/tinyzimmer/kubeconsole/pkg/k8sutils"
 	"k8s.io/client-go/tools/remotecommand"
 )
 
-func (c *controller) RunExecutor() (stdinWriter *io.PipeWriter, stopch chan struct{}, q string) {
-	currentPod := c.getSelectedPod()
+// RunExecutor starts a session in a container of the selected pod in a new
+// tab. The best shell in the container is run, unless prompt is set, which
+// asks for the command to run instead.
+func (c *controller) RunExecutor(prompt bool) (s *session, q string) {
+	ns, currentPod := c.getSelectedPod()
+	if currentPod == "" {
+		q = _cancel
+		return
+	}
 
 	// see if we have multiple containers first
-	pod, err := c.factory.GetPod(c.currentNamespace, currentPod)
+	pod, err := c.factory.GetPod(ns, currentPod)
 	if err != nil {
 		c.errorChan <- newErrorWithStack(err)
+		q = _cancel
 		return
 	}
 
-	var container string
-	if len(pod.Spec.Containers) > 1 {
-		containerNames := make([]string, 0)
-		for _, cont := range pod.Spec.Containers {
-			containerNames = append(containerNames, cont.Name)
-		}
-		container = c.choicePrompt(" Which container to exec into? ", containerNames)
-		if container == _quit || container == _cancel {
-			q = container
-			return
-		}
-		c.renderDefaults()
-	} else {
-		container = pod.Spec.Containers[0].Name
+	container := c.chooseContainer(pod, " Which container to exec into? ")
+	if container == _quit || container == _cancel {
+		q = container
+		return
 	}
 
-	exec, err := c.factory.GetExecutor(c.currentNamespace, currentPod, container)
+	command, q := c.execCommand(ns, currentPod, container, prompt)
+	if q != "" {
+		return
+	}
+
+	c.debug(fmt.Sprintf("Running %v in %s/%s", command, currentPod, container))
+	exec, err := c.factory.GetExecutor(ns, currentPod, container, command, true)
 
 	if err != nil {
 		c.errorChan <- newErrorWithStack(err)
+		q = _cancel
 		return
 	}
 
-	ctx, cancel := context.WithCancel(context.Background())
-	stopch = make(chan struct{})
-
-	// Create pipes for stdin/stdout and a buffer for transferring stdout
-	// to the terminal window
+	// Create pipes for stdin/stdout. Output is interpreted by a screen
+	// model sized to the exec window, which answers the program's queries
+	// about the terminal on its stdin.
 	stdinReader, stdinWriter := io.Pipe()
 	stdoutReader, stdoutWriter := io.Pipe()
-	var buf bytes.Buffer
-	go asyncCopy(ctx, &buf, stdoutReader)
+	screen := newVTScreen(0, 0, func(reply []byte) { stdinWriter.Write(reply) })
+	closed := make(chan struct{})
+	s = &session{
+		kind:  execSession,
+		name:  fmt.Sprintf("%s/%s", currentPod, container),
+		term:  newExecWindow(screen),
+		stdin: stdinWriter,
+		done:  make(chan struct{}),
+		cancel: func() {
+			close(closed)
+			stdinWriter.Close()
+			stdoutReader.Close()
+		},
+	}
+
+	// record the session when asked to, refusing to start it when the
+	// recording can't be made
+	var stdin io.Reader = stdinReader
+	var stdout io.Writer = screen
+	var rec *sessionRecorder
+	if c.record.Dir != "" {
+		width, height := screen.Size()
+		rec, err = newSessionRecorder(c.record, c.factory.CurrentContext(), ns, currentPod, container, command, width, height)
+		if err != nil {
+			c.errorChan <- newErrorWithStack(fmt.Errorf("Not starting a session that can't be recorded: %v", err))
+			return nil, _cancel
+		}
+		c.debug(fmt.Sprintf("Recording session to %s", rec.path))
+		stdin = io.TeeReader(stdinReader, rec.inputs())
+		stdout = io.MultiWriter(screen, rec.output())
+	}
+
+	c.addSession(s)
+
+	// tell the remote end the size of the window now and whenever it
+	// changes
+	done := make(chan struct{})
+	sizes := newExecSizeQueue(done)
+	screen.OnResize(func(width, height int) {
+		sizes.push(width, height)
+		if rec != nil {
+			rec.resize(width, height)
+		}
+	})
+	sizes.push(screen.Size())
+	go c.followExecResizes(s, done)
 
 	// stream stdout to screen
 	go func() {
+		buf := make([]byte, 32*1024)
 		for {
-			select {
-			case <-ctx.Done():
-				// stop streaming and signal the poller to stop blocking
+			n, err := stdoutReader.Read(buf)
+			if n > 0 {
+				stdout.Write(buf[:n])
+				c.renderSession(s)
+			}
+			if err != nil {
+				// stop streaming, close the tab and signal the poller to
+				// stop blocking
 				c.debug("Stopping exec window stream")
-				stopch <- struct{}{}
+				c.finishRecording(rec)
+				c.removeSession(s)
+				close(s.done)
 				return
-			default:
-
-				// der be demons that need cleaning here - especiallfor special events
-				out, _ := decode(buf.Bytes())
-				stripped := stripAnsi(string(out))
-				split := strings.Split(strings.Replace(stripped, "\r\n", "\n", -1), "\n")
-
-				if !cmp.Equal(c.execWindow.Rows, split) {
-					c.execWindow.Rows = split
-					c.execWindow.ScrollBottom()
-					c.mux.Lock()
-					ui.Render(c.execWindow)
-					c.mux.Unlock()
-				}
-
 			}
 		}
 	}()
 
 	// Start the actual command
 	opts := remotecommand.StreamOptions{
-		Stdin:  stdinReader,
-		Stdout: stdoutWriter,
-		Stderr: stdoutWriter,
-		Tty:    true,
+		Stdin:             stdin,
+		Stdout:            stdoutWriter,
+		Stderr:            stdoutWriter,
+		Tty:               true,
+		TerminalSizeQueue: sizes,
 	}
 
 	go func() {
-		defer cancel()
+		defer close(done)
+		defer screen.OnResize(nil)
+		defer stdoutWriter.Close()
+		defer stdinReader.Close()
 		c.debug(fmt.Sprintf("Starting exec stream for %s", currentPod))
-		err = exec.Stream(opts)
-		if err != nil {
-			c.errorChan <- newErrorWithStack(err)
-			time.Sleep(time.Duration(1) * time.Second)
+		err := exec.Stream(opts)
+		select {
+		case <-closed:
+			// closing the session broke the stream
+		default:
+			if err != nil {
+				c.errorChan <- newErrorWithStack(err)
+				time.Sleep(time.Duration(1) * time.Second)
+			}
 		}
 		c.debug(fmt.Sprintf("Finished exec stream for %s", currentPod))
 	}()
@@ -110,20 +155,227 @@ func (c *controller) RunExecutor() (stdinWriter *io.PipeWriter, stopch chan stru
 	return
 }
 
-const ansire = "[\u001B\u009B][[\\]()#;?]*(?:(?:(?:[a-zA-Z\\d]*(?:;[a-zA-Z\\d]*)*)?\u0007)|(?:(?:\\d{1,4}(?:;\\d{0,4})*)?[\\dA-PRZcf-ntqry=><~]))"
+// finishRecording closes the recording of a session once all of its
+// output has been written
+func (c *controller) finishRecording(rec *sessionRecorder) {
+	if rec == nil {
+		return
+	}
+	if err := rec.Close(); err != nil {
+		c.errorChan <- newErrorWithStack(fmt.Errorf("Failed to record session to %s: %v", rec.path, err))
+		return
+	}
+	c.debug(fmt.Sprintf("Recorded session to %s", rec.path))
+}
+
+// execCommand works out what to run in a container, asking for it when
+// prompt is set, and otherwise looking for the best shell in it
+func (c *controller) execCommand(ns, pod, container string, prompt bool) (command []string, q string) {
+	if prompt {
+		input := c.inputPrompt(fmt.Sprintf(" Command to run in %s ", container), "", nil)
+		if input == _quit || input == _cancel {
+			return nil, input
+		}
+		command, err := splitCommand(input)
+		if err != nil {
+			c.errorChan <- newErrorWithStack(err)
+			return nil, _cancel
+		}
+		if len(command) == 0 {
+			return nil, _cancel
+		}
+		return command, ""
+	}
+
+	shell, err := c.factory.DetectShell(ns, pod, container)
+	if err == k8sutils.ErrNoShell {
+		c.debug(fmt.Sprintf("No shell found in %s/%s", pod, container))
+		err = fmt.Errorf("No shell found in container %s, tried %s. Use <E> to run a command instead", container, strings.Join(k8sutils.Shells, ", "))
+	}
+	if err != nil {
+		c.errorChan <- newErrorWithStack(err)
+		return nil, _cancel
+	}
+	c.debug(fmt.Sprintf("Found %s in %s/%s", shell, pod, container))
+	return []string{shell}, ""
+}
+
+// splitCommand splits a command line into arguments the way a shell does
+// for simple quoting: single quotes keep everything, double quotes and
+// backslashes keep the next character
+func splitCommand(line string) ([]string, error) {
+	args := make([]string, 0)
+	var current strings.Builder
+	var quote rune
+	inArg, escaped := false, false
+	for _, r := range line {
+		switch {
+		case escaped:
+			current.WriteRune(r)
+			escaped = false
+		case quote == '\'':
+			if r == quote {
+				quote = 0
+			} else {
+				current.WriteRune(r)
+			}
+		case r == '\\':
+			escaped, inArg = true, true
+		case quote == '"':
+			if r == quote {
+				quote = 0
+			} else {
+				current.WriteRune(r)
+			}
+		case r == '\'' || r == '"':
+			quote, inArg = r, true
+		case r == ' ' || r == '\t':
+			if inArg {
+				args = append(args, current.String())
+				current.Reset()
+				inArg = false
+			}
+		default:
+			current.WriteRune(r)
+			inArg = true
+		}
+	}
+	if quote != 0 || escaped {
+		return nil, fmt.Errorf("Unterminated quote or escape in %q", line)
+	}
+	if inArg {
+		args = append(args, current.String())
+	}
+	return args, nil
+}
+
+// execSizeQueue is the TerminalSizeQueue of an exec session. Only the
+// latest size matters, so sizes the stream hasn't picked up yet are
+// replaced rather than queued.
+type execSizeQueue struct {
+	sizes chan remotecommand.TerminalSize
+	done  <-chan struct{}
+}
 
-var re = regexp.MustCompile(ansire)
+func newExecSizeQueue(done <-chan struct{}) *execSizeQueue {
+	return &execSizeQueue{
+		sizes: make(chan remotecommand.TerminalSize, 1),
+		done:  done,
+	}
+}
 
-func stripAnsi(str string) string {
-	return re.ReplaceAllString(str, "")
+func (q *execSizeQueue) push(width, height int) {
+	size := remotecommand.TerminalSize{Width: uint16(width), Height: uint16(height)}
+	for {
+		select {
+		case q.sizes <- size:
+			return
+		case <-q.sizes:
+		}
+	}
 }
 
-func decode(s []byte) ([]byte, error) {
-	I := bytes.NewReader(s)
-	O := transform.NewReader(I, unicode.UTF8.NewDecoder())
-	d, e := ioutil.ReadAll(O)
-	if e != nil {
-		return nil, e
+// Next blocks until the size changes, returning nil once the session is
+// over
+func (q *execSizeQueue) Next() *remotecommand.TerminalSize {
+	select {
+	case size := <-q.sizes:
+		return &size
+	case <-q.done:
+		return nil
+	}
+}
+
+// followExecResizes refits the window of an exec session as soon as the
+// terminal is resized, until the session is done. Resizes of the SSH
+// console arrive the same way, the server passes window-change requests on
+// to the pty of the console.
+func (c *controller) followExecResizes(s *session, done <-chan struct{}) {
+	winch := make(chan os.Signal, 1)
+	signal.Notify(winch, syscall.SIGWINCH)
+	defer signal.Stop(winch)
+	for {
+		select {
+		case <-done:
+			return
+		case <-winch:
+			c.mux.Lock()
+			s.term.SetRect(sessionRect())
+			s.term.Fit()
+			if c.activeSession() == s {
+				c.renderSessions()
+			}
+			c.mux.Unlock()
+		}
+	}
+}
+
+// execKeys are what keys send to a program in a terminal, other than
+// printable characters and control characters
+var execKeys = map[string]string{
+	"<Space>":         " ",
+	"<Enter>":         "\r",
+	"<Tab>":           "\t",
+	"<Backspace>":     "\x7f",
+	"<C-<Backspace>>": "\x08",
+	"<Escape>":        "\x1b",
+	"<C-4>":           "\x1c",
+	"<C-6>":           "\x1e",
+	"<C-7>":           "\x1f",
+	"<Insert>":        "\x1b[2~",
+	"<Delete>":        "\x1b[3~",
+	"<PageUp>":        "\x1b[5~",
+	"<PageDown>":      "\x1b[6~",
+	"<F1>":            "\x1bOP",
+	"<F2>":            "\x1bOQ",
+	"<F3>":            "\x1bOR",
+	"<F4>":            "\x1bOS",
+	"<F5>":            "\x1b[15~",
+	"<F6>":            "\x1b[17~",
+	"<F7>":            "\x1b[18~",
+	"<F8>":            "\x1b[19~",
+	"<F9>":            "\x1b[20~",
+	"<F10>":           "\x1b[21~",
+	"<F11>":           "\x1b[23~",
+	"<F12>":           "\x1b[24~",
+}
+
+// cursorKeys end the sequences the arrow, home and end keys send, which
+// start differently when the program asks for application mode
+var cursorKeys = map[string]byte{
+	"<Up>":    'A',
+	"<Down>":  'B',
+	"<Right>": 'C',
+	"<Left>":  'D',
+	"<Home>":  'H',
+	"<End>":   'F',
+}
+
+// eventBytes turns a key event into what the key sends to a program in a
+// terminal, returning nil for events that send nothing
+func eventBytes(id string, appCursor bool) []byte {
+	if seq, ok := execKeys[id]; ok {
+		return []byte(seq)
+	}
+	if final, ok := cursorKeys[id]; ok {
+		if appCursor {
+			return []byte{0x1b, 'O', final}
+		}
+		return []byte{0x1b, '[', final}
+	}
+	// alt sends escape first
+	if strings.HasPrefix(id, "<M-") && strings.HasSuffix(id, ">") && len(id) > 4 {
+		if key := eventBytes(id[3:len(id)-1], appCursor); key != nil {
+			return append([]byte{0x1b}, key...)
+		}
+		return nil
+	}
+	// <C-a> to <C-z>
+	if len(id) == 5 && strings.HasPrefix(id, "<C-") && id[3] >= 'a' && id[3] <= 'z' && id[4] == '>' {
+		return []byte{id[3] - 'a' + 1}
+	}
+	if strings.HasPrefix(id, "<") && len(id) > 1 {
+		return nil
 	}
-	return d, nil
+	return []byte(id)
 }
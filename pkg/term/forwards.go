@@ -0,0 +1,371 @@
+package term
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	ui "github.com/gizak/termui/v3"
+	"github.com/tinyzimmer/kubeconsole/pkg/k8sutils"
+)
+
+const (
+	forwardsTitle = " Port Forwards "
+	forwardsNone  = "No port forwards, <s> starts one"
+
+	forwardStarting  = "starting"
+	forwardActive    = "active"
+	forwardResolving = "re-resolving"
+	forwardFailed    = "failed"
+
+	// how long to wait before looking for another pod behind a service
+	forwardRetryInterval = time.Duration(5) * time.Second
+)
+
+var forwardColumns = []string{"LOCAL", "TARGET", "POD", "SENT", "RECEIVED", "STATUS"}
+
+// portForward is a port forward started from the console. It outlives the
+// connection it starts with: forwards to a service move on to another
+// ready pod when theirs goes away.
+type portForward struct {
+	namespace string
+	target    string
+	service   bool
+	ports     []string
+
+	mux      sync.Mutex
+	pod      string
+	local    []string
+	session  *k8sutils.PortForward
+	sent     int64
+	received int64
+	status   string
+	stopped  bool
+}
+
+// summary returns the columns of the forward in the forwards table
+func (f *portForward) summary() []string {
+	f.mux.Lock()
+	defer f.mux.Unlock()
+	sent, received := f.sent, f.received
+	if f.session != nil {
+		s, r := f.session.Bytes()
+		sent, received = sent+s, received+r
+	}
+	target := fmt.Sprintf("pod/%s", f.target)
+	if f.service {
+		target = fmt.Sprintf("svc/%s", f.target)
+	}
+	return []string{
+		strings.Join(f.local, ","),
+		fmt.Sprintf("%s/%s:%s", f.namespace, target, strings.Join(remotePorts(f.ports), ",")),
+		f.pod,
+		byteCount(sent),
+		byteCount(received),
+		f.status,
+	}
+}
+
+func (f *portForward) setStatus(status string) {
+	f.mux.Lock()
+	defer f.mux.Unlock()
+	f.status = status
+}
+
+// stop ends the forward for good
+func (f *portForward) stop() {
+	f.mux.Lock()
+	defer f.mux.Unlock()
+	f.stopped = true
+	if f.session != nil {
+		f.session.Stop()
+	}
+}
+
+func (f *portForward) isStopped() bool {
+	f.mux.Lock()
+	defer f.mux.Unlock()
+	return f.stopped
+}
+
+// startForward starts forwarding ports to a pod or service in the
+// background and adds it to the forwards table
+func (c *controller) startForward(ns, target string, service bool, ports []string) {
+	f := &portForward{
+		namespace: ns,
+		target:    target,
+		service:   service,
+		ports:     ports,
+		status:    forwardStarting,
+	}
+	c.mux.Lock()
+	c.forwards = append(c.forwards, f)
+	c.mux.Unlock()
+	go c.runForward(f)
+}
+
+// runForward keeps a forward up until it is stopped. Pods are forwarded to
+// once, services are resolved to a ready pod again whenever the forward to
+// the last one ends.
+func (c *controller) runForward(f *portForward) {
+	// the pod the last forward failed on, which another is picked over
+	var failed string
+	for !f.isStopped() {
+		pod, ports := f.target, f.ports
+		if f.service {
+			var err error
+			if pod, ports, err = c.factory.ResolveServicePod(f.namespace, f.target, f.ports, failed); err != nil {
+				c.debug(fmt.Sprintf("Failed to resolve a pod for service %s: %v", f.target, err))
+				f.setStatus(fmt.Sprintf("%s: %v", forwardResolving, err))
+				time.Sleep(forwardRetryInterval)
+				continue
+			}
+		}
+
+		session, err := c.factory.PortForward(f.namespace, pod, ports)
+		if err != nil {
+			c.debug(fmt.Sprintf("Failed to forward %v to %s: %v", ports, pod, err))
+			if !f.service {
+				f.setStatus(fmt.Sprintf("%s: %v", forwardFailed, err))
+				return
+			}
+			failed = pod
+			f.setStatus(fmt.Sprintf("%s: %v", forwardResolving, err))
+			time.Sleep(forwardRetryInterval)
+			continue
+		}
+		c.debug(fmt.Sprintf("Forwarding %v to %s/%s", ports, f.namespace, pod))
+
+		f.mux.Lock()
+		if f.stopped {
+			f.mux.Unlock()
+			session.Stop()
+			return
+		}
+		f.pod, f.session, f.status = pod, session, forwardActive
+		// keep the same local ports when moving to another pod, even
+		// when the first ones were picked at random
+		f.local = make([]string, 0)
+		for idx, port := range session.Ports {
+			f.local = append(f.local, fmt.Sprintf("%d", port.Local))
+			f.ports[idx] = fmt.Sprintf("%d:%s", port.Local, remotePorts(f.ports[idx : idx+1])[0])
+		}
+		f.mux.Unlock()
+
+		<-session.Done()
+		f.mux.Lock()
+		sent, received := session.Bytes()
+		f.sent, f.received = f.sent+sent, f.received+received
+		f.session = nil
+		f.mux.Unlock()
+		if f.isStopped() {
+			c.debug(fmt.Sprintf("Stopped forwarding to %s/%s", f.namespace, pod))
+			return
+		}
+		c.debug(fmt.Sprintf("Forward to %s/%s ended: %v", f.namespace, pod, session.Err()))
+		if !f.service {
+			f.setStatus(fmt.Sprintf("%s: %v", forwardFailed, session.Err()))
+			return
+		}
+		failed = pod
+		f.setStatus(forwardResolving)
+	}
+}
+
+// remotePorts returns the remote half of port specs like 8080:80
+func remotePorts(ports []string) []string {
+	remote := make([]string, 0)
+	for _, port := range ports {
+		remote = append(remote, port[strings.LastIndex(port, ":")+1:])
+	}
+	return remote
+}
+
+func byteCount(n int64) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%d B", n)
+	}
+	div, exp := int64(unit), 0
+	for m := n / unit; m >= unit; m /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %ciB", float64(n)/float64(div), "KMGTPE"[exp])
+}
+
+// newForwardList shows the forwards. pollForwards refreshes it while the
+// forwards view is in front.
+func (c *controller) newForwardList() (t *selectableTable) {
+	t = newSelectableTable(forwardColumns...)
+	t.Title = forwardsTitle
+	t.TextStyle = ui.NewStyle(ui.ColorCyan)
+	x, y := ui.TerminalDimensions()
+	t.SetRect(0, 3, x, y-3)
+	t.Data, t.DataStyles = forwardRows(c.forwards)
+	return
+}
+
+// nextForwardsEvent waits for the next event, refreshing the byte counts
+// and statuses every second meanwhile. Prompts read events themselves, so
+// nothing is drawn over them.
+func (c *controller) nextForwardsEvent() ui.Event {
+	ticker := time.NewTicker(time.Second)
+	defer ticker.Stop()
+	for {
+		select {
+		case e := <-c.events:
+			return e
+		case <-ticker.C:
+			c.mux.Lock()
+			c.forwardList.Data, c.forwardList.DataStyles = forwardRows(c.forwards)
+			ui.Render(c.forwardList)
+			c.mux.Unlock()
+		}
+	}
+}
+
+// forwardRows formats the forwards into table rows, colouring them by
+// status
+func forwardRows(forwards []*portForward) (rows [][]string, styles map[int]ui.Style) {
+	rows = make([][]string, 0)
+	styles = make(map[int]ui.Style)
+	if len(forwards) == 0 {
+		rows = append(rows, []string{forwardsNone})
+		return
+	}
+	for idx, f := range forwards {
+		row := f.summary()
+		rows = append(rows, row)
+		switch status := row[len(row)-1]; {
+		case status == forwardActive:
+			styles[idx] = ui.NewStyle(ui.ColorGreen)
+		case strings.HasPrefix(status, forwardFailed):
+			styles[idx] = ui.NewStyle(ui.ColorRed)
+		case strings.HasPrefix(status, forwardResolving):
+			styles[idx] = ui.NewStyle(ui.ColorYellow)
+		}
+	}
+	return
+}
+
+// renderForwards renders the panes of the port forwards view
+func (c *controller) renderForwards() {
+	c.mux.Lock()
+	defer c.mux.Unlock()
+	ui.Render(
+		c.navWindow,
+		c.serverWindow,
+		c.helpWindow,
+		c.forwardList,
+	)
+}
+
+func (c *controller) getSelectedForward() *portForward {
+	if c.forwardList == nil || c.forwardList.SelectedRow >= len(c.forwards) {
+		return nil
+	}
+	return c.forwards[c.forwardList.SelectedRow]
+}
+
+// promptForward asks which ports to forward to a pod or service and starts
+// forwarding them
+func (c *controller) promptForward(ns, target string, service bool) (q string) {
+	kind := "pod"
+	if service {
+		kind = "svc"
+	}
+	input := c.inputPrompt(fmt.Sprintf(" Ports to forward to %s/%s, e.g. 8080:80 :5432 ", kind, target), "", nil)
+	if input == _cancel || input == _quit {
+		return input
+	}
+	ports := strings.Fields(input)
+	if len(ports) == 0 {
+		return _cancel
+	}
+	c.startForward(ns, target, service, ports)
+	return ""
+}
+
+// promptNewForward asks for a pod or service in the current namespace to
+// forward to
+func (c *controller) promptNewForward() (q string) {
+	if c.currentNamespace == "" {
+		c.errorChan <- newErrorWithStack(fmt.Errorf("Select a namespace first"))
+		return
+	}
+	input := c.inputPrompt(fmt.Sprintf(" Forward to pod/NAME or svc/NAME in %s ", c.currentNamespace), "", nil)
+	if input == _cancel || input == _quit {
+		return input
+	}
+	parts := strings.SplitN(strings.TrimSpace(input), "/", 2)
+	if len(parts) != 2 || parts[1] == "" {
+		c.errorChan <- newErrorWithStack(fmt.Errorf("Expected pod/NAME or svc/NAME, got %q", input))
+		return
+	}
+	switch parts[0] {
+	case "pod", "pods", "po":
+		return c.promptForward(c.currentNamespace, parts[1], false)
+	case "svc", "service", "services":
+		return c.promptForward(c.currentNamespace, parts[1], true)
+	}
+	c.errorChan <- newErrorWithStack(fmt.Errorf("Can only forward to pods and services, not %s", parts[0]))
+	return
+}
+
+// stopForward stops the selected forward and drops it from the table
+func (c *controller) stopForward() {
+	f := c.getSelectedForward()
+	if f == nil {
+		return
+	}
+	f.stop()
+	c.mux.Lock()
+	defer c.mux.Unlock()
+	for idx := range c.forwards {
+		if c.forwards[idx] == f {
+			c.forwards = append(c.forwards[:idx], c.forwards[idx+1:]...)
+			break
+		}
+	}
+	c.forwardList.Data, c.forwardList.DataStyles = forwardRows(c.forwards)
+}
+
+func (c *controller) pollForwards() (next int) {
+	c.focusTab(forwardsTab)
+	c.helpWindow.Text = forwardsHelpText
+	c.forwardList = c.newForwardList()
+	for {
+		ui.Clear()
+		c.renderForwards()
+		e := c.nextForwardsEvent()
+		switch e.ID {
+
+		// reload
+		case "r":
+			c.forwardList = c.newForwardList()
+
+		// start a new forward
+		case "s":
+			if q := c.promptNewForward(); q == _quit {
+				return quitView
+			}
+			c.mux.Lock()
+			c.forwardList.Data, c.forwardList.DataStyles = forwardRows(c.forwards)
+			c.mux.Unlock()
+
+		// stop the selected forward
+		case "x":
+			c.stopForward()
+
+		default:
+			if next, ok := c.switchView(e.ID); ok {
+				return next
+			}
+			if q := c.checkCommon(c.forwardList, e.ID); q == _quit {
+				return quitView
+			}
+		}
+	}
+}
@@ -0,0 +1,252 @@
+package term
+
+import (
+	"fmt"
+	"strings"
+
+	ui "github.com/gizak/termui/v3"
+	"github.com/tinyzimmer/kubeconsole/pkg/k8sutils"
+	"sigs.k8s.io/yaml"
+)
+
+const (
+	historyTitle   = " Rollout History "
+	historyLoading = "Loading revisions..."
+	diffTitle      = " Pod Template Diff "
+)
+
+var revisionColumns = []string{"REVISION", "REPLICASET", "PODS", "AGE", "CHANGE-CAUSE"}
+
+func (c *controller) newRevisionList(deployment string) (t *selectableTable) {
+	t = newSelectableTable(revisionColumns...)
+	t.Title = fmt.Sprintf(" %s   Deployment: %s ", historyTitle, deployment)
+	t.TextStyle = ui.NewStyle(ui.ColorCyan)
+	x, y := ui.TerminalDimensions()
+	t.SetRect(0, 3, x, y/2)
+	t.Data = [][]string{{historyLoading}}
+
+	c.revisions = nil
+	go func() {
+		revisions, err := c.factory.ListRevisions(c.currentNamespace, deployment)
+		if err != nil {
+			c.errorChan <- newErrorWithStack(err)
+			return
+		}
+		c.debug(fmt.Sprintf("Retrieved %d revisions of %s", len(revisions), deployment))
+		c.revisions = revisions
+		t.Data, t.DataStyles = revisionRows(revisions, c.markedRevision)
+		if t == c.revisionList {
+			c.mux.Lock()
+			ui.Render(t)
+			c.mux.Unlock()
+		}
+	}()
+	return
+}
+
+func newDiffWindow() *styledList {
+	pane := newStyledList()
+	pane.Title = diffTitle
+	pane.Rows = plainRows([]string{
+		"<enter> diffs the selected revision against the one before it,",
+		"or against the marked revision if there is one",
+	}, ui.NewStyle(ui.ColorWhite))
+	x, y := ui.TerminalDimensions()
+	pane.SetRect(0, y/2, x, y-3)
+	return pane
+}
+
+// revisionRows formats a rollout history into table rows, highlighting
+// the marked revision
+func revisionRows(revisions []k8sutils.Revision, marked int64) (rows [][]string, styles map[int]ui.Style) {
+	rows = make([][]string, 0)
+	styles = make(map[int]ui.Style)
+	for idx, rev := range revisions {
+		number := fmt.Sprintf("%d", rev.Number)
+		if rev.Number == marked {
+			number = fmt.Sprintf("%s *", number)
+			styles[idx] = ui.NewStyle(ui.ColorMagenta)
+		}
+		rows = append(rows, []string{
+			number,
+			rev.ReplicaSet,
+			fmt.Sprintf("%d", rev.Replicas),
+			age(rev.Created),
+			rev.ChangeCause,
+		})
+	}
+	return
+}
+
+// renderHistory renders the panes of the rollout history view
+func (c *controller) renderHistory() {
+	c.mux.Lock()
+	defer c.mux.Unlock()
+	ui.Render(
+		c.navWindow,
+		c.serverWindow,
+		c.helpWindow,
+		c.revisionList,
+		c.revisionDiff,
+	)
+}
+
+func (c *controller) getSelectedRevision() *k8sutils.Revision {
+	if c.revisionList == nil || c.revisionList.SelectedRow >= len(c.revisions) {
+		return nil
+	}
+	return &c.revisions[c.revisionList.SelectedRow]
+}
+
+// diffRevisions shows what changed in the pod template between the
+// selected revision and either the marked one or the one before it
+func (c *controller) diffRevisions() {
+	selected := c.getSelectedRevision()
+	if selected == nil {
+		return
+	}
+	var other *k8sutils.Revision
+	for idx := range c.revisions {
+		rev := &c.revisions[idx]
+		if c.markedRevision != 0 && rev.Number == c.markedRevision && rev != selected {
+			other = rev
+			break
+		}
+		if c.markedRevision == 0 && rev.Number < selected.Number {
+			other = rev
+			break
+		}
+	}
+	if other == nil {
+		c.revisionDiff.Rows = plainRows([]string{
+			fmt.Sprintf("Nothing to compare revision %d against", selected.Number),
+		}, ui.NewStyle(ui.ColorYellow))
+		return
+	}
+
+	// always show the change going forwards in time
+	older, newer := other, selected
+	if older.Number > newer.Number {
+		older, newer = newer, older
+	}
+	before, err := yaml.Marshal(older.Template)
+	if err != nil {
+		c.errorChan <- newErrorWithStack(err)
+		return
+	}
+	after, err := yaml.Marshal(newer.Template)
+	if err != nil {
+		c.errorChan <- newErrorWithStack(err)
+		return
+	}
+	c.debug(fmt.Sprintf("Diffing revision %d against %d", older.Number, newer.Number))
+	c.revisionDiff.Title = fmt.Sprintf(" %s   Revision %d -> %d ", diffTitle, older.Number, newer.Number)
+	c.revisionDiff.Rows = diffRows(
+		strings.Split(strings.TrimSpace(string(before)), "\n"),
+		strings.Split(strings.TrimSpace(string(after)), "\n"),
+	)
+	c.revisionDiff.ScrollTop()
+}
+
+// markRevision marks (or unmarks) the selected revision as the one to
+// diff against
+func (c *controller) markRevision() {
+	selected := c.getSelectedRevision()
+	if selected == nil {
+		return
+	}
+	if c.markedRevision == selected.Number {
+		c.markedRevision = 0
+	} else {
+		c.markedRevision = selected.Number
+	}
+	c.revisionList.Data, c.revisionList.DataStyles = revisionRows(c.revisions, c.markedRevision)
+}
+
+// rollbackDeployment rolls the deployment back to the selected revision
+// once confirmed
+func (c *controller) rollbackDeployment(deployment string) (q string) {
+	selected := c.getSelectedRevision()
+	if selected == nil {
+		return
+	}
+	if selected.Number == c.revisions[0].Number {
+		c.errorChan <- newErrorWithStack(fmt.Errorf("Revision %d is already the current revision", selected.Number))
+		return
+	}
+	q = c.confirmPrompt(fmt.Sprintf("Roll %s back to revision %d?", deployment, selected.Number))
+	if q != confirmYes {
+		return
+	}
+	c.debug(fmt.Sprintf("Rolling back %s to revision %d", deployment, selected.Number))
+	if err := c.factory.RollbackDeployment(c.currentNamespace, deployment, selected.Number); err != nil {
+		c.debug(fmt.Sprintf("Failed to roll back %s: %v", deployment, err))
+		c.errorChan <- newErrorWithStack(err)
+		return ""
+	}
+	c.debug(fmt.Sprintf("Rolled back %s to revision %d", deployment, selected.Number))
+	c.revisionList = c.newRevisionList(deployment)
+	return ""
+}
+
+// pollHistory shows the rollout history of the selected deployment until
+// the user backs out to the workloads view
+func (c *controller) pollHistory() (q string) {
+	workload := c.getSelectedWorkload()
+	if workload == nil {
+		return
+	}
+	if workload.Kind != k8sutils.KindDeployment {
+		c.errorChan <- newErrorWithStack(fmt.Errorf("Rollout history is only kept for deployments"))
+		return
+	}
+	deployment := workload.Name
+	c.helpWindow.Text = historyHelpText
+	c.markedRevision = 0
+	c.revisionList = c.newRevisionList(deployment)
+	c.revisionDiff = newDiffWindow()
+	var focus scrollable = c.revisionList
+	defer func() {
+		c.revisionList = nil
+		c.helpWindow.Text = workloadsHelpText
+	}()
+
+	for {
+		ui.Clear()
+		c.renderHistory()
+		e := <-c.events
+		switch e.ID {
+
+		case "r":
+			c.revisionList = c.newRevisionList(deployment)
+			focus = c.revisionList
+
+		case "<Escape>", "w":
+			return
+
+		case "<Tab>":
+			if focus == c.revisionList {
+				focus = c.revisionDiff
+			} else {
+				focus = c.revisionList
+			}
+
+		case enter:
+			c.diffRevisions()
+
+		case "m":
+			c.markRevision()
+
+		case "u":
+			if q = c.rollbackDeployment(deployment); q == _quit {
+				return
+			}
+			focus = c.revisionList
+
+		default:
+			if q = c.checkCommon(focus, e.ID); q == _quit {
+				return
+			}
+		}
+	}
+}
@@ -0,0 +1,121 @@
+package term
+
+import (
+	"fmt"
+
+	ui "github.com/gizak/termui/v3"
+	"github.com/tinyzimmer/kubeconsole/pkg/k8sutils"
+)
+
+const (
+	ingressesTitle   = " Ingresses "
+	ingressesLoading = "Loading ingresses..."
+	ingressesNoNS    = "Select a namespace first"
+)
+
+var ingressColumns = []string{"INGRESS", "HOST", "PATH", "BACKEND", "TLS SECRET", "PROBLEM"}
+
+func (c *controller) newIngressList() (t *selectableTable) {
+	t = newSelectableTable(ingressColumns...)
+	t.Title = fmt.Sprintf(" %s   Namespace: %s ", ingressesTitle, c.currentNamespace)
+	t.TextStyle = ui.NewStyle(ui.ColorCyan)
+	x, y := ui.TerminalDimensions()
+	t.SetRect(0, 3, x, y-3)
+
+	c.ingressRoutes = nil
+	if c.currentNamespace == "" {
+		t.Data = [][]string{{ingressesNoNS}}
+		return
+	}
+	t.Data = [][]string{{ingressesLoading}}
+
+	go func() {
+		routes, err := c.factory.ListIngressRoutes(c.currentNamespace)
+		if err != nil {
+			c.errorChan <- newErrorWithStack(err)
+			return
+		}
+		c.debug(fmt.Sprintf("Retrieved %d ingress routes in %s", len(routes), c.currentNamespace))
+		c.ingressRoutes = routes
+		t.Data, t.DataStyles = ingressRows(routes)
+		if c.navWindow.ActiveTabIndex == ingressesTab {
+			c.mux.Lock()
+			ui.Render(t)
+			c.mux.Unlock()
+		}
+	}()
+	return
+}
+
+// ingressRows formats ingress routes into table rows, colouring the routes
+// whose backend is broken
+func ingressRows(routes []k8sutils.IngressRoute) (rows [][]string, styles map[int]ui.Style) {
+	rows = make([][]string, 0)
+	styles = make(map[int]ui.Style)
+	for idx, route := range routes {
+		rows = append(rows, []string{
+			route.Ingress,
+			route.Host,
+			route.Path,
+			fmt.Sprintf("%s:%s", route.Service, route.Port),
+			route.TLSSecret,
+			route.Problem,
+		})
+		if route.Problem != "" {
+			styles[idx] = ui.NewStyle(ui.ColorRed)
+		}
+	}
+	return
+}
+
+// renderIngresses renders the panes of the ingresses view
+func (c *controller) renderIngresses() {
+	c.mux.Lock()
+	defer c.mux.Unlock()
+	ui.Render(
+		c.navWindow,
+		c.serverWindow,
+		c.helpWindow,
+		c.ingressList,
+	)
+}
+
+func (c *controller) getSelectedIngressRoute() *k8sutils.IngressRoute {
+	if c.ingressList == nil || c.ingressList.SelectedRow >= len(c.ingressRoutes) {
+		return nil
+	}
+	return &c.ingressRoutes[c.ingressList.SelectedRow]
+}
+
+func (c *controller) pollIngresses() (next int) {
+	c.focusTab(ingressesTab)
+	c.helpWindow.Text = ingressesHelpText
+	c.ingressList = c.newIngressList()
+	for {
+		ui.Clear()
+		c.renderIngresses()
+		e := <-c.events
+		switch e.ID {
+
+		// reload
+		case "r":
+			c.ingressList = c.newIngressList()
+
+		// jump to the service behind the route
+		case enter:
+			if route := c.getSelectedIngressRoute(); route != nil {
+				c.debug(fmt.Sprintf("Jumping to service %s behind %s%s", route.Service, route.Host, route.Path))
+				c.serviceToSelect = route.Service
+				return servicesTab
+			}
+
+		default:
+			if next, ok := c.switchView(e.ID); ok {
+				return next
+			}
+			if q := c.checkCommon(c.ingressList, e.ID); q == _quit {
+				return quitView
+			}
+		}
+	}
+}
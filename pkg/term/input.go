@@ -1,13 +1,10 @@
 package term
 
 import (
-	"context"
 	"fmt"
-	"io"
-	"os"
 
 	ui "github.com/gizak/termui/v3"
-	"github.com/gizak/termui/v3/widgets"
+	"github.com/tinyzimmer/kubeconsole/pkg/k8sutils"
 )
 
 const (
@@ -26,12 +23,9 @@ const (
 	_cancel = "CANCEL"
 )
 
-var logContext context.Context
-var logCancel func()
-var focus *widgets.List
-var logsPaused bool
+var focus scrollable
 
-func (c *controller) checkCommon(focus *widgets.List, event string) (q string) {
+func (c *controller) checkCommon(focus scrollable, event string) (q string) {
 	switch event {
 	// quit
 	case "q", ctrlC:
@@ -54,36 +48,108 @@ func (c *controller) checkCommon(focus *widgets.List, event string) (q string) {
 
 	case pageDown:
 		c.focusScroll(focus, pageDown)
+
+	// list the keys that work in every view
+	case "?":
+		c.showKeys()
 	}
 	return ""
 }
 
-func (c *controller) pollNamespaces(ch chan string) {
+// showKeys shows the keys that work in every view over the view in front
+// until any key is pressed
+func (c *controller) showKeys() {
+	c.mux.Lock()
+	ui.Render(newKeysWindow())
+	c.mux.Unlock()
+	for e := range c.events {
+		if e.Type == ui.KeyboardEvent {
+			break
+		}
+	}
+	ui.Clear()
+	c.renderView()
+}
+
+// quitView is returned by a view to end the program rather than switch to
+// another view
+const quitView = -1
+
+// viewKeys are the keys that switch to another view from every view
+var viewKeys = map[string]int{
+	"n": namespacesTab,
+	"p": podsTab,
+	"w": workloadsTab,
+	"i": servicesTab,
+	"g": ingressesTab,
+	"m": configMapsTab,
+	"S": secretsTab,
+	"u": resourcesTab,
+	"v": eventsTab,
+	"o": nodesTab,
+	"F": forwardsTab,
+	"c": consoleTab,
+}
+
+// switchView returns the view a key switches to, if it switches to a view
+// other than the one in front
+func (c *controller) switchView(key string) (next int, ok bool) {
+	next, ok = viewKeys[key]
+	return next, ok && next != c.navWindow.ActiveTabIndex
+}
+
+// runViews shows one view after another until one of them quits. Views
+// return the view to switch to instead of calling it, so switching views
+// doesn't nest their loops.
+func (c *controller) runViews(next int) {
+	for next != quitView {
+		ui.Clear()
+		switch next {
+		case namespacesTab:
+			next = c.displayNamespaceList()
+		case podsTab:
+			next = c.pollPods()
+		case workloadsTab:
+			next = c.pollWorkloads()
+		case servicesTab:
+			next = c.pollServices()
+		case ingressesTab:
+			next = c.pollIngresses()
+		case configMapsTab:
+			next = c.pollConfigMaps()
+		case secretsTab:
+			next = c.pollSecrets()
+		case resourcesTab:
+			next = c.pollResources()
+		case eventsTab:
+			next = c.pollEvents()
+		case nodesTab:
+			next = c.pollNodes()
+		case forwardsTab:
+			next = c.pollForwards()
+		case consoleTab:
+			next = c.pollConsole()
+		}
+	}
+}
+
+func (c *controller) pollNamespaces(ch chan string) (next int) {
+	c.helpWindow.Text = namespacesHelpText
+	c.renderDefaults()
 	c.renderNamespaceList()
-	uiEvents := ui.PollEvents()
+	uiEvents := c.events
 	for {
 		e := <-uiEvents
 		switch e.ID {
 
 		// switch to pod view
 		case "p", "<Escape>":
-			c.navWindow.FocusRight()
-			ui.Clear()
-			c.pollPods()
-			return
+			return podsTab
 
 		// reload
 		case "r":
 			c.namespaceList = c.newNamespaceList()
 
-		// render console
-		case "c":
-			c.navWindow.FocusRight()
-			c.navWindow.FocusRight()
-			ui.Render(c.navWindow, c.console)
-			c.pollConsole()
-			return
-
 		case "s":
 			ctxs, err := c.factory.AvailableContexts()
 			if err != nil {
@@ -92,7 +158,7 @@ func (c *controller) pollNamespaces(ch chan string) {
 			}
 			ctx := c.choicePrompt("Which context?", ctxs)
 			if ctx == _quit {
-				return
+				return quitView
 			} else if ctx == _cancel {
 				continue
 			}
@@ -106,34 +172,35 @@ func (c *controller) pollNamespaces(ch chan string) {
 			c.serverWindow = c.newAPIServerWindow()
 			c.renderDefaults()
 			c.renderNamespaceList()
-			// we need to start a new poller that doesn't have the enter
-			// from the choice prompt
-			uiEvents = ui.PollEvents()
 
 		// load pods for selcted namespace
 		case enter:
 			c.currentNamespace = c.getSelectedNamespace()
+			c.podFilter = k8sutils.PodFilter{}
 			ch <- c.currentNamespace
-			c.navWindow.FocusRight()
-			ui.Clear()
-			c.renderDefaults()
 			c.debug(fmt.Sprintf("Fetching pods for %s", c.currentNamespace))
-			c.pollPods()
-			return
+			return podsTab
 
 		default:
+			if next, ok := c.switchView(e.ID); ok {
+				return next
+			}
 			if q := c.checkCommon(c.namespaceList, e.ID); q == _quit {
-				cancelIfNotNil(logCancel)
-				return
+				return quitView
 			}
 		}
 		c.renderNamespaceList()
 	}
 }
 
-func (c *controller) pollPods() {
-	uiEvents := ui.PollEvents()
+func (c *controller) pollPods() (next int) {
+	c.focusTab(podsTab)
+	c.helpWindow.Text = helpText
+	uiEvents := c.events
 	focus = c.podList
+	c.mux.Lock()
+	c.updateSessionTabs()
+	c.mux.Unlock()
 	for {
 		ui.Clear()
 		c.renderDefaults()
@@ -144,56 +211,95 @@ func (c *controller) pollPods() {
 		case "r":
 			ch := make(chan string)
 			c.podList = c.newPodList(ch)
-			ch <- c.currentNamespace
+			ch <- c.podNamespace()
+
+		// drop the workload or node filter and show every pod
+		case "a":
+			if c.podFilter != (k8sutils.PodFilter{}) {
+				c.podFilter = k8sutils.PodFilter{}
+				ch := make(chan string)
+				c.podList = c.newPodList(ch)
+				ch <- c.currentNamespace
+			}
 
-		// bring up namespace menu
-		case "n":
-			cancelIfNotNil(logCancel)
-			c.displayNamespaceList()
-			return
+		// forward ports to the selected pod and switch to port forwards view
+		case "F":
+			if ns, pod := c.getSelectedPod(); pod != "" {
+				q := c.promptForward(ns, pod, false)
+				if q == _quit {
+					return quitView
+				} else if q == _cancel {
+					continue
+				}
+			}
+			return forwardsTab
 
 		// switch between panes - will add detail window too
 		case "<Tab>":
 			c.switchPane()
 
-		// render console
-		case "c":
-			cancelIfNotNil(logCancel)
-			c.navWindow.FocusRight()
-			c.mux.Lock()
-			ui.Render(c.navWindow, c.console)
-			c.mux.Unlock()
-			c.pollConsole()
-			return
-
 			// tail pod logs
 		case "t":
-			cancelIfNotNil(logCancel)
-			logContext, logCancel = context.WithCancel(context.Background())
 			if q := c.tailPod(); q == _quit {
-				cancelIfNotNil(logCancel)
-				return
+				return quitView
 			}
 
-		// get pod details
+		// delete or evict the selected pod
+		case "d":
+			if q := c.deletePod(); q == _quit {
+				return quitView
+			}
+
+		// get pod details, or go back to the session in the sessions pane
 		case enter:
+			if focus == c.sessionPane() {
+				c.resumeSession()
+				continue
+			}
 			c.debug("Loading pod...")
 			c.selectPod()
 
-		case "e":
-			cancelIfNotNil(logCancel)
-			stdin, stopch, q := c.RunExecutor()
+		// exec into the selected pod, running the best shell found or a
+		// command that is asked for
+		case "e", "E":
+			s, q := c.RunExecutor(e.ID == "E")
 			if q == _quit {
-				return
+				return quitView
 			} else if q != _cancel {
-				c.pollExecutor(stdin, stopch)
-				return
+				c.pollExecutor(s)
+			}
+
+		// switch between sessions
+		case "[":
+			c.switchSession(-1)
+		case "]":
+			c.switchSession(1)
+
+		// close the session that is showing
+		case "X":
+			if q := c.closeSession(); q == _quit {
+				return quitView
 			}
 
+		// copy files to or from the selected pod
+		case "C":
+			if q := c.copyFiles(); q == _quit {
+				return quitView
+			}
+
+		// switch the details pane to the pod's manifest and back
+		case "y":
+			c.togglePodManifest()
+
 		default:
+			if c.showPodManifest && c.manifestKeys(c.podManifestWindow, c.podManifest, e.ID) {
+				continue
+			}
+			if next, ok := c.switchView(e.ID); ok {
+				return next
+			}
 			if q := c.checkCommon(focus, e.ID); q == _quit {
-				cancelIfNotNil(logCancel)
-				return
+				return quitView
 			}
 
 		}
@@ -201,66 +307,57 @@ func (c *controller) pollPods() {
 	}
 }
 
-func (c *controller) pollExecutor(stdin *io.PipeWriter, stch chan struct{}) {
-
-	// redirect all stdin to the terminal,
-	ctx, cancel := context.WithCancel(context.Background())
-
-	go asyncCopy(ctx, stdin, os.Stdin)
-	//	events := ui.PollEvents()
-
-	// wait for a stop from the exec stream
-	//
-	// I'd like to take more control over the stdin copy and feed back
-	// page events to scroll through the command history
+// pollExecutor attaches the keyboard to an exec session until it ends or
+// is detached from, leaving it running in its tab. It returns to the pods
+// view's loop rather than starting another.
+func (c *controller) pollExecutor(s *session) {
+	defer func() { c.helpWindow.Text = helpText }()
+	c.helpWindow.Text = execHelpText
+	c.showSession(s)
+	ui.Clear()
+	c.renderDefaults()
 	for {
 		select {
-		case <-stch:
-			cancel()
-			stdin.Close()
-			c.pollPods()
+		case <-s.done:
 			return
-		// case e := <-events:
-		// 	switch e.ID {
-		// 	case pageUp:
-		// 		c.execWindow.ScrollPageUp()
-		// 		ui.Render(c.execWindow)
-		// 	case pageDown:
-		// 		c.execWindow.ScrollPageDown()
-		// 		ui.Render(c.execWindow)
-		// 	}
-		default:
+		case e := <-c.events:
+			if e.Type == ui.ResizeEvent {
+				c.resizeDefaults()
+				c.helpWindow.Text = execHelpText
+				c.renderDefaults()
+				continue
+			}
+			if e.Type != ui.KeyboardEvent {
+				continue
+			}
+			if e.ID == detachKey {
+				c.debug(fmt.Sprintf("Detaching from %s", s.name))
+				return
+			}
+			if input := eventBytes(e.ID, s.term.Screen.AppCursorKeys()); input != nil {
+				s.stdin.Write(input)
+			}
 		}
 	}
 }
 
-func (c *controller) pollConsole() {
-	uiEvents := ui.PollEvents()
+func (c *controller) pollConsole() (next int) {
+	c.focusTab(consoleTab)
+	c.helpWindow.Text = consoleHelpText
+	c.mux.Lock()
+	ui.Render(c.navWindow, c.helpWindow, c.console)
+	c.mux.Unlock()
+	uiEvents := c.events
 	c.consoleFocused = true
+	defer func() { c.consoleFocused = false }()
 
 	for {
 		e := <-uiEvents
-		switch e.ID {
-
-		case "n":
-			c.consoleFocused = false
-			c.navWindow.FocusLeft()
-			c.navWindow.FocusLeft()
-			ui.Render(c.navWindow)
-			c.displayNamespaceList()
-			return
-
-		case "p":
-			c.consoleFocused = false
-			c.navWindow.FocusLeft()
-			ui.Clear()
-			c.pollPods()
-			return
-
-		default:
-			if q := c.checkCommon(c.console, e.ID); q == _quit {
-				return
-			}
+		if next, ok := c.switchView(e.ID); ok {
+			return next
+		}
+		if q := c.checkCommon(c.console, e.ID); q == _quit {
+			return quitView
 		}
 		c.renderConsole()
 
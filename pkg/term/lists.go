@@ -1,20 +1,30 @@
 package term
 
 import (
+	"context"
 	"fmt"
+	"strings"
+	"time"
+	"unicode"
 
 	ui "github.com/gizak/termui/v3"
 	"github.com/gizak/termui/v3/widgets"
+	"github.com/tinyzimmer/kubeconsole/pkg/k8sutils"
 )
 
 const (
 	namespaceTitle   = " Namespaces "
 	namespaceLoading = "Loading namespaces..."
 
-	podsTitle   = " Pods "
-	podsLoading = "Loading pods..."
+	podsTitle          = " Pods "
+	podsLoading        = "Loading pods..."
+	allNamespacesTitle = "<all>"
+
+	ageRefresh = time.Duration(10) * time.Second
 )
 
+var podColumns = []string{"NAME", "READY", "STATUS", "RESTARTS", "AGE", "IP", "NODE"}
+
 func (c *controller) newNamespaceList() (l *widgets.List) {
 	l = widgets.NewList()
 	l.Title = namespaceTitle
@@ -51,37 +61,151 @@ func (c *controller) newNamespaceList() (l *widgets.List) {
 	return
 }
 
-func (c *controller) newPodList(ch chan string) (l *widgets.List) {
-	l = widgets.NewList()
+func (c *controller) newPodList(ch chan string) (l *selectableTable) {
+	l = newSelectableTable(podColumns...)
 	l.Title = podsTitle
+	c.pods = nil
 
 	go func() {
 		for {
 			select {
 			case selection := <-ch:
-				c.debug(fmt.Sprintf("Got namespace selection, fetching pods for %s", selection))
-				l.Rows = []string{podsLoading}
+				c.debug(fmt.Sprintf("Got namespace selection, watching pods in %s", selection))
+				l.Data = [][]string{{podsLoading}}
 				ui.Render(l)
-				rows, err := c.factory.ListPods(selection)
+				cancelIfNotNil(c.podWatchCancel)
+				ctx, cancel := context.WithCancel(context.Background())
+				updates, err := c.factory.WatchPods(selection, c.podFilter, ctx)
 				if err != nil {
+					cancel()
 					c.errorChan <- newErrorWithStack(err)
 					return
 				}
-				l.Title = fmt.Sprintf(" %s   Namespace: %s ", podsTitle, selection)
-				l.Rows = rows
-
-				c.debug("Rendering pod list")
-				c.mux.Lock()
-				ui.Render(l)
-				c.mux.Unlock()
+				c.podWatchCancel = cancel
+				allNamespaces := selection == ""
+				if allNamespaces {
+					l.Header = append([]string{"NAMESPACE"}, podColumns...)
+					l.Title = fmt.Sprintf(" %s   Namespace: %s ", podsTitle, allNamespacesTitle)
+				} else {
+					l.Title = fmt.Sprintf(" %s   Namespace: %s ", podsTitle, selection)
+				}
+				if c.podFilter.LabelSelector != "" {
+					l.Title = fmt.Sprintf("%s  Selector: %s ", l.Title, c.podFilter.LabelSelector)
+				}
+				if c.podFilter.FieldSelector != "" {
+					l.Title = fmt.Sprintf("%s  Fields: %s ", l.Title, c.podFilter.FieldSelector)
+				}
+				go c.syncPodList(l, updates, allNamespaces)
 			}
 		}
 	}()
 
-	l.Rows = []string{}
 	l.TextStyle = ui.NewStyle(ui.ColorCyan)
-	l.WrapText = true
 	x, y := ui.TerminalDimensions()
 	l.SetRect(0, 3, x/2, y/2)
 	return
 }
+
+// syncPodList replaces the rows of the pod table every time the watch
+// sends an update, keeping the cursor on the same pod where possible.
+// Rows are also rebuilt on a timer so the age column keeps ticking.
+func (c *controller) syncPodList(l *selectableTable, updates <-chan []k8sutils.PodSummary, allNamespaces bool) {
+	ticker := time.NewTicker(ageRefresh)
+	defer ticker.Stop()
+	var pods []k8sutils.PodSummary
+	for {
+		select {
+		case update, ok := <-updates:
+			if !ok {
+				c.debug("Pod watch closed")
+				return
+			}
+			pods = update
+		case <-ticker.C:
+		}
+
+		var selected k8sutils.PodSummary
+		jumping := false
+		if l == c.podList && c.podToSelect != "" {
+			// another view asked for this pod to be selected
+			selected = k8sutils.PodSummary{Namespace: c.currentNamespace, Name: c.podToSelect}
+			c.podToSelect = ""
+			jumping = true
+		} else if l == c.podList && l.SelectedRow < len(c.pods) {
+			selected = c.pods[l.SelectedRow]
+		}
+		l.Data, l.DataStyles = podRows(pods, allNamespaces)
+		l.SelectedRow = 0
+		found := false
+		for idx, pod := range pods {
+			if pod.Namespace == selected.Namespace && pod.Name == selected.Name {
+				l.SelectedRow = idx
+				found = true
+				break
+			}
+		}
+
+		if l == c.podList {
+			c.pods = pods
+			if jumping && found {
+				c.selectPod()
+			}
+			if c.navWindow.ActiveTabIndex == podsTab {
+				c.mux.Lock()
+				ui.Render(l)
+				c.mux.Unlock()
+			}
+		}
+	}
+}
+
+// setPodStatus overwrites the status shown for a pod until the watch
+// catches up with the change
+func (c *controller) setPodStatus(ns, pod, status string) {
+	// the status column moves over one when namespaces are shown
+	col := len(c.podList.Header) - len(podColumns) + 2
+	for idx, summary := range c.pods {
+		if summary.Namespace == ns && summary.Name == pod && idx < len(c.podList.Data) {
+			c.podList.Data[idx][col] = status
+			c.podList.DataStyles[idx] = ui.NewStyle(podStatusColor(status))
+		}
+	}
+}
+
+// podRows formats pod summaries into table rows, colouring the pods that
+// are not healthy
+func podRows(pods []k8sutils.PodSummary, withNamespace bool) (rows [][]string, styles map[int]ui.Style) {
+	rows = make([][]string, 0)
+	styles = make(map[int]ui.Style)
+	for idx, pod := range pods {
+		row := []string{
+			pod.Name,
+			pod.Ready(),
+			pod.Status,
+			fmt.Sprintf("%d", pod.Restarts),
+			age(pod.Created),
+			pod.IP,
+			pod.Node,
+		}
+		if withNamespace {
+			row = append([]string{pod.Namespace}, row...)
+		}
+		rows = append(rows, row)
+		if !pod.Healthy() {
+			styles[idx] = ui.NewStyle(podStatusColor(pod.Status))
+		}
+	}
+	return
+}
+
+// podStatusColor is yellow for pods on their way up or down, and red for
+// anything else that is not healthy
+func podStatusColor(status string) ui.Color {
+	switch {
+	case status == "Pending", status == "ContainerCreating", status == "PodInitializing",
+		status == "Terminating", status == "Evicting", status == "Running",
+		strings.HasPrefix(status, "Init:") && len(status) > 5 && unicode.IsDigit(rune(status[5])):
+		return ui.ColorYellow
+	}
+	return ui.ColorRed
+}
@@ -1,104 +1,97 @@
 package term
 
 import (
-	"bytes"
 	"context"
 	"fmt"
 	"io"
-	"strings"
 
 	ui "github.com/gizak/termui/v3"
 	"github.com/gizak/termui/v3/widgets"
-	"github.com/google/go-cmp/cmp"
 )
 
-func (c *controller) newLogWindow() (*widgets.List, chan string) {
-	ch := make(chan string)
+func newLogWindow() *widgets.List {
 	logs := widgets.NewList()
 	logs.Title = logTitle
-	x, y := ui.TerminalDimensions()
-	logs.SetRect(0, y/2, x, y-3)
+	logs.SetRect(sessionRect())
 	logs.TextStyle = ui.NewStyle(ui.ColorBlue)
 	logs.WrapText = true
 	logs.SelectedRowStyle.Fg = ui.ColorMagenta
-	go func() {
-		for {
-			select {
-			case ev := <-ch:
-				if ev == clearEvent {
-					c.debug("Clearing current log window")
-					logs.Rows = make([]string, 0)
-				} else {
-					newRows := strings.Split(strings.Replace(ev, "\r\n", "\n", -1), "\n")
-					if !cmp.Equal(logs.Rows, newRows) {
-						logs.Rows = newRows
-						logs.ScrollBottom()
-						logs.ScrollPageDown()
-
-						c.mux.Lock()
-						ui.Render(logs)
-						c.mux.Unlock()
-					}
-				}
-			}
-		}
-	}()
-	return logs, ch
+	return logs
 }
 
 func (c *controller) tailPod() (q string) {
-	if empty(c.podList) {
+	ns, podName := c.getSelectedPod()
+	if podName == "" {
 		return
 	}
-	podName := c.getSelectedPod()
-	pod, err := c.factory.GetPod(c.currentNamespace, podName)
+	pod, err := c.factory.GetPod(ns, podName)
 	if err != nil {
 		c.errorChan <- newErrorWithStack(err)
 		return
 	}
-	if len(pod.Spec.Containers) == 1 {
-		c.startLogStream(podName, "")
-	} else {
-		//c.errorChan <- newErrorWithStack(errors.New("Multi-container pods not yet supported"))
-		containerNames := make([]string, 0)
-		for _, container := range pod.Spec.Containers {
-			containerNames = append(containerNames, container.Name)
-		}
-		choice := c.choicePrompt(" Which container to tail? ", containerNames)
-		if choice == _quit || choice == _cancel {
-			return choice
-		}
-		c.startLogStream(podName, choice)
+	container := c.chooseContainer(pod, " Which container to tail? ")
+	if container == _quit || container == _cancel {
+		return container
 	}
+	c.startLogStream(ns, podName, container)
 	return
 }
 
-func (c *controller) startLogStream(pod, container string) {
+// startLogStream tails a container in a new session, leaving any others
+// running
+func (c *controller) startLogStream(ns, pod, container string) {
 	c.debug(fmt.Sprintf("Starting log stream for pod: %s  container: %s", pod, container))
-	c.resetLogWindow()
-	logsPaused = false
-	c.logChan <- clearEvent
-	c.logChan <- fmt.Sprintf("Fetching logs for %s...\n", pod)
-	stream, err := c.factory.GetLogStream(c.currentNamespace, pod, container, logContext)
+	ctx, cancel := context.WithCancel(context.Background())
+	stream, err := c.factory.GetLogStream(ns, pod, container, ctx)
 	if err != nil {
+		cancel()
 		c.errorChan <- newErrorWithStack(err)
 		return
 	}
+	s := &session{
+		kind: logSession,
+		name: fmt.Sprintf("%s/%s", pod, container),
+		logs: newLogWindow(),
+		cancel: func() {
+			cancel()
+			stream.Close()
+		},
+	}
+	c.addSession(s)
 	c.debug(fmt.Sprintf("Retrieved log stream for %s, begining sync to window", pod))
-	go c.streamLogsToWindow(logContext, stream)
+	go c.streamLogs(ctx, s, stream)
 }
 
-func (c *controller) streamLogsToWindow(ctx context.Context, stream io.ReadCloser) {
+// streamLogs copies a log stream into its session until it ends or the
+// session is closed
+func (c *controller) streamLogs(ctx context.Context, s *session, stream io.ReadCloser) {
 	defer stream.Close()
-	buf := new(bytes.Buffer)
-	go asyncCopy(logContext, buf, stream)
+	buf := make([]byte, 32*1024)
 	for {
-		select {
-		case <-ctx.Done():
-			c.debug("Got cancel for log stream")
+		n, err := stream.Read(buf)
+		if ctx.Err() != nil {
+			c.debug(fmt.Sprintf("Got cancel for log stream %s", s.name))
+			return
+		}
+		c.mux.Lock()
+		if n > 0 {
+			s.appendLogs(string(buf[:n]))
+		}
+		if err != nil {
+			if s.partial != "" {
+				s.appendLogs("\n")
+			}
+			s.ended = true
+			c.updateSessionTabs()
+		}
+		c.mux.Unlock()
+		c.renderSession(s)
+		if err != nil {
+			if err != io.EOF {
+				c.errorChan <- newErrorWithStack(err)
+			}
+			c.debug(fmt.Sprintf("Log stream for %s ended", s.name))
 			return
-		default:
-			c.logChan <- buf.String()
 		}
 	}
 }
@@ -0,0 +1,297 @@
+package term
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+
+	ui "github.com/gizak/termui/v3"
+	"github.com/tinyzimmer/kubeconsole/pkg/k8sutils"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"sigs.k8s.io/yaml"
+)
+
+const (
+	manifestTitle   = " Manifest "
+	manifestLoading = "Loading manifest..."
+)
+
+var (
+	manifestPlainStyle   = ui.NewStyle(ui.ColorWhite)
+	manifestKeyStyle     = ui.NewStyle(ui.ColorCyan)
+	manifestStringStyle  = ui.NewStyle(ui.ColorGreen)
+	manifestLiteralStyle = ui.NewStyle(ui.ColorMagenta)
+	manifestCommentStyle = ui.NewStyle(ui.ColorBlue)
+	manifestMatchStyle   = ui.NewStyle(ui.ColorBlack, ui.ColorYellow)
+)
+
+// podResource addresses pods through the dynamic client, which unlike the
+// typed client keeps the apiVersion and kind of what it returns
+var podResource = k8sutils.APIResource{Version: "v1", Resource: "pods", Kind: "Pod", Namespaced: true}
+
+// manifest is a complete object shown as a document, along with what is
+// being searched for in it
+type manifest struct {
+	object  *unstructured.Unstructured
+	query   string
+	matches []int
+	match   int
+}
+
+// rows serializes the object as YAML or JSON and colours it. managedFields
+// are noise unless you are debugging server side apply, so they are left
+// out unless asked for. Secret values are masked, they are only revealed
+// in the secrets view. Lines matching the query are highlighted and
+// remembered for jumping between.
+func (m *manifest) rows(asJSON, managedFields bool) ([][]span, error) {
+	obj := m.object.DeepCopy()
+	maskSecretData(obj, nil)
+	if !managedFields {
+		unstructured.RemoveNestedField(obj.Object, "metadata", "managedFields")
+	}
+	var rows [][]span
+	if asJSON {
+		out, err := json.MarshalIndent(obj.Object, "", "  ")
+		if err != nil {
+			return nil, err
+		}
+		rows = jsonRows(string(out))
+	} else {
+		out, err := yaml.Marshal(obj.Object)
+		if err != nil {
+			return nil, err
+		}
+		rows = yamlRows(strings.TrimSuffix(string(out), "\n"))
+	}
+
+	m.matches = nil
+	if m.query != "" {
+		for idx, row := range rows {
+			if highlighted, ok := highlight(row, m.query); ok {
+				rows[idx] = highlighted
+				m.matches = append(m.matches, idx)
+			}
+		}
+	}
+	if m.match >= len(m.matches) {
+		m.match = 0
+	}
+	return rows, nil
+}
+
+// yamlRows colours a YAML document line by line. The lines of block
+// scalars are tracked so that their content isn't mistaken for keys.
+func yamlRows(doc string) [][]span {
+	rows := make([][]span, 0)
+	blockColumn := -1
+	for _, line := range strings.Split(doc, "\n") {
+		if blockColumn >= 0 {
+			indent := len(line) - len(strings.TrimLeft(line, " "))
+			if indent > blockColumn || strings.TrimSpace(line) == "" {
+				rows = append(rows, []span{{text: line, style: manifestStringStyle}})
+				continue
+			}
+			blockColumn = -1
+		}
+		var row []span
+		row, blockColumn = yamlLine(line)
+		rows = append(rows, row)
+	}
+	return rows
+}
+
+// yamlLine colours a single line of YAML. When the line opens a block
+// scalar, the column its content must be indented past is returned, or -1
+// otherwise.
+func yamlLine(line string) ([]span, int) {
+	rest := strings.TrimLeft(line, " ")
+	for strings.HasPrefix(rest, "- ") {
+		rest = strings.TrimLeft(rest[2:], " ")
+	}
+	prefix := line[:len(line)-len(rest)]
+	row := []span{{text: prefix, style: manifestPlainStyle}}
+	if strings.HasPrefix(rest, "#") {
+		return append(row, span{text: rest, style: manifestCommentStyle}), -1
+	}
+
+	// a quoted key ends at its closing quote, anything else at the first
+	// colon followed by a space
+	key, value := "", rest
+	end := 0
+	if strings.HasPrefix(rest, `"`) || strings.HasPrefix(rest, "'") {
+		end = len(rest)
+		if idx := strings.Index(rest[1:], rest[:1]); idx >= 0 {
+			end = idx + 2
+		}
+	}
+	if idx := strings.Index(rest[end:], ": "); idx >= 0 && (end == 0 || idx == 0) {
+		key, value = rest[:end+idx+1], rest[end+idx+1:]
+	} else if strings.HasSuffix(rest, ":") && (end == 0 || end == len(rest)-1) {
+		key, value = rest, ""
+	}
+	if key != "" {
+		row = append(row, span{text: key, style: manifestKeyStyle})
+	}
+	if value != "" {
+		row = append(row, span{text: value, style: scalarStyle(strings.TrimSpace(value))})
+	}
+
+	trimmed := strings.TrimSpace(value)
+	if strings.HasPrefix(trimmed, "|") || strings.HasPrefix(trimmed, ">") {
+		return row, len(prefix)
+	}
+	return row, -1
+}
+
+// jsonRows colours an indented JSON document line by line
+func jsonRows(doc string) [][]span {
+	rows := make([][]span, 0)
+	for _, line := range strings.Split(doc, "\n") {
+		rest := strings.TrimLeft(line, " ")
+		row := []span{{text: line[:len(line)-len(rest)], style: manifestPlainStyle}}
+		value := rest
+		if strings.HasPrefix(rest, `"`) {
+			if idx := strings.Index(rest, `": `); idx >= 0 {
+				row = append(row, span{text: rest[:idx+2], style: manifestKeyStyle})
+				value = rest[idx+2:]
+			}
+		}
+		row = append(row, span{text: value, style: scalarStyle(strings.TrimSuffix(strings.TrimSpace(value), ","))})
+		rows = append(rows, row)
+	}
+	return rows
+}
+
+// scalarStyle picks the colour of a value by what it looks like
+func scalarStyle(value string) ui.Style {
+	switch value {
+	case "", "{", "}", "[", "]", "{}", "[]":
+		return manifestPlainStyle
+	case "true", "false", "null", "~":
+		return manifestLiteralStyle
+	}
+	if _, err := strconv.ParseFloat(value, 64); err == nil {
+		return manifestLiteralStyle
+	}
+	return manifestStringStyle
+}
+
+// highlight marks every case-insensitive match of query in a row, and
+// reports whether there were any
+func highlight(row []span, query string) ([]span, bool) {
+	var text strings.Builder
+	for _, s := range row {
+		text.WriteString(s.text)
+	}
+	line := text.String()
+	matched := make([]bool, len(line))
+	found := false
+	for idx := 0; idx+len(query) <= len(line); idx++ {
+		if strings.EqualFold(line[idx:idx+len(query)], query) {
+			for i := idx; i < idx+len(query); i++ {
+				matched[i] = true
+			}
+			found = true
+		}
+	}
+	if !found {
+		return row, false
+	}
+
+	// split the spans wherever a match starts or ends
+	highlighted := make([]span, 0)
+	offset := 0
+	for _, s := range row {
+		start := 0
+		for idx := 1; idx <= len(s.text); idx++ {
+			if idx == len(s.text) || matched[offset+idx] != matched[offset+start] {
+				style := s.style
+				if matched[offset+start] {
+					style = manifestMatchStyle
+				}
+				highlighted = append(highlighted, span{text: s.text[start:idx], style: style})
+				start = idx
+			}
+		}
+		offset += len(s.text)
+	}
+	return highlighted, true
+}
+
+// drawManifest fills a pane with a manifest, scrolled to the current search
+// match if there is one
+func (c *controller) drawManifest(pane *styledList, m *manifest) {
+	format := "YAML"
+	if c.manifestAsJSON {
+		format = "JSON"
+	}
+	rows, err := m.rows(c.manifestAsJSON, c.manifestManagedFields)
+	if err != nil {
+		pane.Rows = plainRows([]string{err.Error()}, ui.NewStyle(ui.ColorRed))
+		return
+	}
+	focused := strings.HasPrefix(pane.Title, " * ")
+	pane.Rows = rows
+	pane.Title = fmt.Sprintf(" %s   %s/%s   %s ", manifestTitle, m.object.GetKind(), m.object.GetName(), format)
+	if focused {
+		pane.Title = fmt.Sprintf(" * %s ", pane.Title)
+	}
+	if m.query != "" {
+		if len(m.matches) == 0 {
+			pane.Title = fmt.Sprintf("%s  /%s (no matches) ", pane.Title, m.query)
+			return
+		}
+		pane.Title = fmt.Sprintf("%s  /%s (%d of %d) ", pane.Title, m.query, m.match+1, len(m.matches))
+		pane.ScrollTo(m.matches[m.match])
+	}
+}
+
+// manifestKeys handles the keys shared by every pane showing a manifest,
+// and reports whether the key was one of them
+func (c *controller) manifestKeys(pane *styledList, m *manifest, key string) bool {
+	if m == nil {
+		return false
+	}
+	switch key {
+
+	// toggle between YAML and JSON
+	case "J":
+		c.manifestAsJSON = !c.manifestAsJSON
+		pane.ScrollTop()
+
+	// show or hide managedFields
+	case "M":
+		c.manifestManagedFields = !c.manifestManagedFields
+		pane.ScrollTop()
+
+	// search the document
+	case "/":
+		query := c.inputPrompt(" Search manifest ", m.query, nil)
+		if query == _cancel || query == _quit {
+			return true
+		}
+		m.query, m.match = query, 0
+		pane.ScrollTop()
+
+	// jump to the next match
+	case "N":
+		if len(m.matches) == 0 {
+			return true
+		}
+		m.match = (m.match + 1) % len(m.matches)
+
+	default:
+		return false
+	}
+	c.drawManifest(pane, m)
+	return true
+}
+
+func newPodManifestWindow() *styledList {
+	pane := newStyledList()
+	pane.Title = manifestTitle
+	x, y := ui.TerminalDimensions()
+	pane.SetRect(x/2, 3, x, y/2)
+	return pane
+}
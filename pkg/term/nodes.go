@@ -0,0 +1,187 @@
+package term
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"text/template"
+
+	ui "github.com/gizak/termui/v3"
+	"github.com/gizak/termui/v3/widgets"
+	"github.com/tinyzimmer/kubeconsole/pkg/k8sutils"
+)
+
+const (
+	nodesTitle     = " Nodes "
+	nodesLoading   = "Loading nodes..."
+	nodeDetsTitle  = " Node Details "
+	nodeNameFilter = "spec.nodeName=%s"
+)
+
+var nodeColumns = []string{"NAME", "STATUS", "ROLES", "AGE", "VERSION", "PODS", "CPU REQUESTS", "MEMORY REQUESTS", "TAINTS"}
+
+func (c *controller) newNodeList() (t *selectableTable) {
+	t = newSelectableTable(nodeColumns...)
+	t.Title = nodesTitle
+	t.TextStyle = ui.NewStyle(ui.ColorCyan)
+	x, y := ui.TerminalDimensions()
+	t.SetRect(0, 3, x, y/2)
+	t.Data = [][]string{{nodesLoading}}
+
+	c.nodes = nil
+	go func() {
+		nodes, err := c.factory.ListNodes()
+		if err != nil {
+			c.errorChan <- newErrorWithStack(err)
+			return
+		}
+		c.debug(fmt.Sprintf("Retrieved %d nodes", len(nodes)))
+		c.nodes = nodes
+		t.Data, t.DataStyles = nodeRows(nodes)
+		if c.navWindow.ActiveTabIndex == nodesTab {
+			c.mux.Lock()
+			ui.Render(t)
+			c.mux.Unlock()
+		}
+	}()
+	return
+}
+
+func newNodeDetailsWindow() (*widgets.List, chan string) {
+	pane, ch := newDetailsWindow()
+	pane.Title = nodeDetsTitle
+	x, y := ui.TerminalDimensions()
+	pane.SetRect(0, y/2, x, y-3)
+	return pane, ch
+}
+
+// nodeRows formats node summaries into table rows, colouring nodes that
+// aren't ready red and cordoned nodes yellow
+func nodeRows(nodes []k8sutils.NodeSummary) (rows [][]string, styles map[int]ui.Style) {
+	rows = make([][]string, 0)
+	styles = make(map[int]ui.Style)
+	for idx, n := range nodes {
+		rows = append(rows, []string{
+			n.Name,
+			n.Status,
+			n.Roles,
+			age(n.Created),
+			n.KubeletVersion,
+			fmt.Sprintf("%d", n.Pods),
+			fmt.Sprintf("%s/%s (%d%%)", n.CPURequests, n.CPUAllocatable, n.CPUPercent),
+			fmt.Sprintf("%s/%s (%d%%)", n.MemoryRequests, n.MemoryAllocatable, n.MemoryPercent),
+			strings.Join(n.Taints, ","),
+		})
+		if !n.Ready() {
+			styles[idx] = ui.NewStyle(ui.ColorRed)
+		} else if n.Unschedulable {
+			styles[idx] = ui.NewStyle(ui.ColorYellow)
+		}
+	}
+	return
+}
+
+// renderNodes renders the panes of the nodes view
+func (c *controller) renderNodes() {
+	c.mux.Lock()
+	defer c.mux.Unlock()
+	ui.Render(
+		c.navWindow,
+		c.serverWindow,
+		c.helpWindow,
+		c.nodeList,
+		c.nodeDetails,
+	)
+}
+
+func (c *controller) getSelectedNode() *k8sutils.NodeSummary {
+	if c.nodeList == nil || c.nodeList.SelectedRow >= len(c.nodes) {
+		return nil
+	}
+	return &c.nodes[c.nodeList.SelectedRow]
+}
+
+func (c *controller) selectNode() {
+	node := c.getSelectedNode()
+	if node == nil {
+		return
+	}
+	c.debug(fmt.Sprintf("Fetching details for node %s", node.Name))
+	c.nodeDetailsChan <- fmt.Sprintf("Loading details for %s...\n", node.Name)
+	go c.getNodeDetails(node.Name)
+}
+
+func (c *controller) getNodeDetails(name string) {
+	details, err := c.factory.GetNode(name)
+	if err != nil {
+		c.errorChan <- newErrorWithStack(err)
+		return
+	}
+	var buf bytes.Buffer
+	t := template.Must(template.New("node-details").Parse(nodeDetailsTemplate))
+	if err = t.Execute(&buf, details); err != nil {
+		c.errorChan <- newErrorWithStack(err)
+		return
+	}
+	c.nodeDetailsChan <- buf.String()
+}
+
+// filterPodsByNode points the pod list at the pods scheduled on the
+// selected node, in every namespace
+func (c *controller) filterPodsByNode() bool {
+	node := c.getSelectedNode()
+	if node == nil {
+		return false
+	}
+	c.debug(fmt.Sprintf("Filtering pods by node %s", node.Name))
+	c.podFilter = k8sutils.PodFilter{FieldSelector: fmt.Sprintf(nodeNameFilter, node.Name)}
+	ch := make(chan string)
+	c.podList = c.newPodList(ch)
+	ch <- c.podNamespace()
+	return true
+}
+
+func (c *controller) pollNodes() (next int) {
+	c.focusTab(nodesTab)
+	c.helpWindow.Text = nodesHelpText
+	c.nodeList = c.newNodeList()
+	for {
+		ui.Clear()
+		c.renderNodes()
+		e := <-c.events
+		switch e.ID {
+
+		// reload
+		case "r":
+			c.nodeList = c.newNodeList()
+
+		// get node details
+		case enter:
+			c.selectNode()
+
+		// show the pods scheduled on the node
+		case "f":
+			if c.filterPodsByNode() {
+				return podsTab
+			}
+
+		case "C":
+			if q := c.cordonNode(); q == _quit {
+				return quitView
+			}
+
+		case "D":
+			if q := c.drainNode(); q == _quit {
+				return quitView
+			}
+
+		default:
+			if next, ok := c.switchView(e.ID); ok {
+				return next
+			}
+			if q := c.checkCommon(c.nodeList, e.ID); q == _quit {
+				return quitView
+			}
+		}
+	}
+}
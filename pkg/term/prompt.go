@@ -1,13 +1,23 @@
 package term
 
 import (
+	"fmt"
+	"unicode/utf8"
+
 	ui "github.com/gizak/termui/v3"
 	"github.com/gizak/termui/v3/widgets"
 )
 
+const (
+	confirmYes = "Yes"
+	confirmNo  = "No"
+
+	inputHelp = "<enter> submit | <esc> cancel"
+)
+
 func (c *controller) choicePrompt(title string, choices []string) (sel string) {
 	ui.Clear()
-	c.renderDefaults()
+	c.renderView()
 	prompt := widgets.NewList()
 	prompt.Title = title
 	prompt.Rows = choices
@@ -18,8 +28,7 @@ func (c *controller) choicePrompt(title string, choices []string) (sel string) {
 	for {
 		ui.Render(prompt)
 
-		events := ui.PollEvents()
-		e := <-events
+		e := <-c.events
 		switch e.ID {
 		case enter:
 			return prompt.Rows[prompt.SelectedRow]
@@ -32,3 +41,59 @@ func (c *controller) choicePrompt(title string, choices []string) (sel string) {
 		}
 	}
 }
+
+// confirmPrompt asks a yes/no question, defaulting to no. It returns
+// confirmYes, or _cancel if the answer was no.
+func (c *controller) confirmPrompt(question string) (sel string) {
+	sel = c.choicePrompt(fmt.Sprintf(" %s ", question), []string{confirmNo, confirmYes})
+	if sel == confirmNo {
+		return _cancel
+	}
+	return
+}
+
+// inputPrompt reads a line of text from the user. When accept is not nil,
+// only the characters it allows can be typed. Escape returns _cancel and
+// Ctrl-C returns _quit.
+func (c *controller) inputPrompt(title, initial string, accept func(rune) bool) (input string) {
+	ui.Clear()
+	c.renderView()
+	prompt := widgets.NewParagraph()
+	prompt.Title = title
+	prompt.TextStyle = ui.NewStyle(ui.ColorCyan)
+	x, y := ui.TerminalDimensions()
+	prompt.SetRect(x/4, y/2-3, (x - x/4), y/2+3)
+
+	input = initial
+	for {
+		prompt.Text = fmt.Sprintf("> %s_\n\n%s", input, inputHelp)
+		ui.Render(prompt)
+
+		e := <-c.events
+		switch e.ID {
+		case enter:
+			return input
+		case "<Escape>":
+			return _cancel
+		case ctrlC:
+			return _quit
+		case "<Backspace>", "<C-<Backspace>>":
+			if len(input) > 0 {
+				_, size := utf8.DecodeLastRuneInString(input)
+				input = input[:len(input)-size]
+			}
+		case "<Space>":
+			if accept == nil || accept(' ') {
+				input += " "
+			}
+		default:
+			if e.Type != ui.KeyboardEvent || utf8.RuneCountInString(e.ID) != 1 {
+				continue
+			}
+			r, _ := utf8.DecodeRuneInString(e.ID)
+			if accept == nil || accept(r) {
+				input += e.ID
+			}
+		}
+	}
+}
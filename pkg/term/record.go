@@ -0,0 +1,192 @@
+package term
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+)
+
+// asciicastVersion is the version of the asciicast format recordings are
+// written in
+const asciicastVersion = 2
+
+// asciicast event types
+const (
+	castOutput = "o"
+	castInput  = "i"
+	castResize = "r"
+)
+
+// unsafeFileChars are replaced in the names of recordings, context names
+// in particular can hold slashes and colons
+var unsafeFileChars = regexp.MustCompile(`[^A-Za-z0-9._-]+`)
+
+// RecordOptions turns on recording of exec sessions
+type RecordOptions struct {
+	// Dir is where recordings are written, sessions are not recorded when
+	// it is empty
+	Dir string
+	// Input records what is typed into sessions as well as their output
+	Input bool
+}
+
+// castHeader is the first line of an asciicast v2 file
+type castHeader struct {
+	Version   int               `json:"version"`
+	Width     int               `json:"width"`
+	Height    int               `json:"height"`
+	Timestamp int64             `json:"timestamp"`
+	Command   string            `json:"command,omitempty"`
+	Title     string            `json:"title,omitempty"`
+	Env       map[string]string `json:"env,omitempty"`
+}
+
+// sessionRecorder writes an exec session to an asciicast v2 file, which
+// holds a header and then one timestamped event per line
+type sessionRecorder struct {
+	mux     sync.Mutex
+	file    *os.File
+	path    string
+	start   time.Time
+	input   bool
+	pending map[string][]byte
+	err     error
+}
+
+// newSessionRecorder creates the recording of a session in a container,
+// named after the context, namespace, pod, container and start time. When
+// another session to the same container started in the same millisecond,
+// a number is added to keep the name unique.
+func newSessionRecorder(opts RecordOptions, context, ns, pod, container string, command []string, width, height int) (*sessionRecorder, error) {
+	if err := os.MkdirAll(opts.Dir, 0700); err != nil {
+		return nil, err
+	}
+	start := time.Now()
+	parts := []string{context, ns, pod, container, start.UTC().Format("20060102T150405.000Z")}
+	for idx := range parts {
+		parts[idx] = unsafeFileChars.ReplaceAllString(parts[idx], "_")
+	}
+	name := strings.Join(parts, "_")
+	path := filepath.Join(opts.Dir, name+".cast")
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0600)
+	for n := 2; os.IsExist(err); n++ {
+		path = filepath.Join(opts.Dir, fmt.Sprintf("%s-%d.cast", name, n))
+		file, err = os.OpenFile(path, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0600)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	header, err := json.Marshal(castHeader{
+		Version:   asciicastVersion,
+		Width:     width,
+		Height:    height,
+		Timestamp: start.Unix(),
+		Command:   strings.Join(command, " "),
+		Title:     fmt.Sprintf("%s %s/%s/%s", context, ns, pod, container),
+		Env:       map[string]string{"TERM": os.Getenv("TERM")},
+	})
+	if err == nil {
+		_, err = file.Write(append(header, '\n'))
+	}
+	if err != nil {
+		file.Close()
+		return nil, err
+	}
+	return &sessionRecorder{
+		file:    file,
+		path:    path,
+		start:   start,
+		input:   opts.Input,
+		pending: make(map[string][]byte),
+	}, nil
+}
+
+// event writes an event, holding back the end of data that stops in the
+// middle of a UTF-8 character until the rest of it arrives
+func (r *sessionRecorder) event(kind string, data []byte) {
+	r.mux.Lock()
+	defer r.mux.Unlock()
+	if r.err != nil {
+		return
+	}
+	data = append(r.pending[kind], data...)
+	end := len(data)
+	for cut := 1; cut < utf8.UTFMax && cut <= len(data); cut++ {
+		if utf8.RuneStart(data[len(data)-cut]) {
+			if !utf8.FullRune(data[len(data)-cut:]) {
+				end = len(data) - cut
+			}
+			break
+		}
+	}
+	r.pending[kind] = append([]byte(nil), data[end:]...)
+	if end == 0 {
+		return
+	}
+	r.write(kind, string(data[:end]))
+}
+
+func (r *sessionRecorder) write(kind, data string) {
+	line, err := json.Marshal([]interface{}{
+		time.Since(r.start).Seconds(),
+		kind,
+		data,
+	})
+	if err == nil {
+		_, err = r.file.Write(append(line, '\n'))
+	}
+	r.err = err
+}
+
+// output records what the session shows, it is an io.Writer for teeing
+// the output stream
+func (r *sessionRecorder) output() writerFunc {
+	return func(p []byte) (int, error) {
+		r.event(castOutput, p)
+		return len(p), nil
+	}
+}
+
+// inputs records what is typed into the session, when asked to
+func (r *sessionRecorder) inputs() writerFunc {
+	return func(p []byte) (int, error) {
+		if r.input {
+			r.event(castInput, p)
+		}
+		return len(p), nil
+	}
+}
+
+// resize records the terminal changing size
+func (r *sessionRecorder) resize(width, height int) {
+	r.mux.Lock()
+	defer r.mux.Unlock()
+	if r.err == nil {
+		r.write(castResize, fmt.Sprintf("%dx%d", width, height))
+	}
+}
+
+// Close finishes the recording, returning the first error writing it.
+// Whatever was held back waiting for the rest of a character is written
+// as it is, the session won't be sending the rest.
+func (r *sessionRecorder) Close() error {
+	r.mux.Lock()
+	defer r.mux.Unlock()
+	for _, kind := range []string{castOutput, castInput} {
+		if len(r.pending[kind]) > 0 && r.err == nil {
+			r.write(kind, string(r.pending[kind]))
+		}
+		delete(r.pending, kind)
+	}
+	if err := r.file.Close(); r.err == nil {
+		r.err = err
+	}
+	return r.err
+}
@@ -0,0 +1,83 @@
+package term
+
+import (
+	"bufio"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"testing"
+)
+
+// castEvents reads the events of a recording back, without the header
+func castEvents(t *testing.T, path string) [][]interface{} {
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	events := make([][]interface{}, 0)
+	scanner := bufio.NewScanner(f)
+	scanner.Scan()
+	for scanner.Scan() {
+		var event []interface{}
+		if err := json.Unmarshal(scanner.Bytes(), &event); err != nil {
+			t.Fatal(err)
+		}
+		events = append(events, event)
+	}
+	return events
+}
+
+func TestRecorderHoldsBackSplitCharacters(t *testing.T) {
+	dir, err := ioutil.TempDir("", "record")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	rec, err := newSessionRecorder(RecordOptions{Dir: dir}, "ctx", "ns", "pod", "container", []string{"sh"}, 80, 24)
+	if err != nil {
+		t.Fatal(err)
+	}
+	euro := []byte("€")
+	rec.output().Write(append([]byte("a"), euro[:1]...))
+	rec.output().Write(euro[1:])
+	// the session ends part way through a character
+	rec.output().Write(euro[:2])
+	if err := rec.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	events := castEvents(t, rec.path)
+	if len(events) != 3 {
+		t.Fatalf("expected 3 events, got %v", events)
+	}
+	for idx, expected := range []string{"a", "€"} {
+		if events[idx][2] != expected {
+			t.Errorf("expected event %d to hold %q, got %q", idx, expected, events[idx][2])
+		}
+	}
+	if events[2][2] == "" {
+		t.Errorf("expected the incomplete character to be written when closing")
+	}
+}
+
+func TestRecordingNamesAreUnique(t *testing.T) {
+	dir, err := ioutil.TempDir("", "record")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	paths := make(map[string]bool)
+	for i := 0; i < 3; i++ {
+		rec, err := newSessionRecorder(RecordOptions{Dir: dir}, "ctx", "ns", "pod", "container", []string{"sh"}, 80, 24)
+		if err != nil {
+			t.Fatalf("expected sessions started together to be recorded, got %v", err)
+		}
+		defer rec.Close()
+		if paths[rec.path] {
+			t.Errorf("%s was used twice", rec.path)
+		}
+		paths[rec.path] = true
+	}
+}
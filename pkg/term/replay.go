@@ -0,0 +1,319 @@
+package term
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+
+	ui "github.com/gizak/termui/v3"
+	"github.com/gizak/termui/v3/widgets"
+)
+
+const (
+	replayHelpText = "<space> pause/resume | <left>/<right> seek 5s | <+>/<-> speed | <.> step | <home>/<end> start/end | <q>uit"
+
+	// how far the arrow keys seek, in seconds of the recording
+	replaySeek = 5.0
+	// how often the clock in the status bar moves between events
+	replayTick = time.Duration(200) * time.Millisecond
+
+	replayMinSpeed = 1.0 / 16
+	replayMaxSpeed = 64.0
+
+	// the largest terminal a recording may ask for, sizes come from the
+	// file and every cell of the screen is allocated
+	castMaxSize = 1000
+)
+
+// castEvent is an event of an asciicast recording
+type castEvent struct {
+	time float64
+	kind string
+	data string
+}
+
+// readCast reads an asciicast v2 recording
+func readCast(path string) (header castHeader, events []castEvent, err error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return
+	}
+	defer f.Close()
+
+	reader := bufio.NewReader(f)
+	line, err := reader.ReadBytes('\n')
+	if err != nil && err != io.EOF {
+		return
+	}
+	if err = json.Unmarshal(line, &header); err != nil {
+		return header, nil, fmt.Errorf("%s is not an asciicast recording: %v", path, err)
+	}
+	if header.Version != asciicastVersion {
+		return header, nil, fmt.Errorf("%s is asciicast version %d, only version %d can be played", path, header.Version, asciicastVersion)
+	}
+	if err = checkCastSize(header.Width, header.Height); err != nil {
+		return header, nil, fmt.Errorf("%s: %v", path, err)
+	}
+
+	events = make([]castEvent, 0)
+	for lineNo := 2; ; lineNo++ {
+		line, err = reader.ReadBytes('\n')
+		if len(line) > 0 && string(line) != "\n" {
+			var raw []interface{}
+			if jsonErr := json.Unmarshal(line, &raw); jsonErr != nil {
+				return header, nil, fmt.Errorf("%s:%d: %v", path, lineNo, jsonErr)
+			}
+			event, ok := parseCastEvent(raw)
+			if !ok {
+				return header, nil, fmt.Errorf("%s:%d: not an event", path, lineNo)
+			}
+			if event.kind == castResize {
+				if _, _, sizeErr := castSize(event.data); sizeErr != nil {
+					return header, nil, fmt.Errorf("%s:%d: %v", path, lineNo, sizeErr)
+				}
+			}
+			events = append(events, event)
+		}
+		if err == io.EOF {
+			return header, events, nil
+		} else if err != nil {
+			return header, nil, err
+		}
+	}
+}
+
+// castSize reads the size of a resize event, like 80x24
+func castSize(data string) (width, height int, err error) {
+	if _, err = fmt.Sscanf(data, "%dx%d", &width, &height); err != nil {
+		return 0, 0, fmt.Errorf("Invalid terminal size %q", data)
+	}
+	return width, height, checkCastSize(width, height)
+}
+
+func checkCastSize(width, height int) error {
+	if width < 1 || height < 1 || width > castMaxSize || height > castMaxSize {
+		return fmt.Errorf("Terminal size %dx%d is outside of 1x1 to %dx%d", width, height, castMaxSize, castMaxSize)
+	}
+	return nil
+}
+
+func parseCastEvent(raw []interface{}) (event castEvent, ok bool) {
+	if len(raw) != 3 {
+		return
+	}
+	if event.time, ok = raw[0].(float64); !ok {
+		return
+	}
+	if event.kind, ok = raw[1].(string); !ok {
+		return
+	}
+	event.data, ok = raw[2].(string)
+	return
+}
+
+// player plays a recording back into a screen model
+type player struct {
+	header castHeader
+	events []castEvent
+	title  string
+
+	// the next event to play, and how far into the recording the screen
+	// is in seconds
+	next   int
+	clock  float64
+	speed  float64
+	paused bool
+
+	screen *vtScreen
+	pane   *terminalPane
+	status *widgets.Paragraph
+}
+
+// Replay plays an asciicast recording back in the terminal, at speed times
+// the speed it was recorded at. termui must be initialized.
+func Replay(path string, speed float64) error {
+	header, events, err := readCast(path)
+	if err != nil {
+		return err
+	}
+	if speed < replayMinSpeed || speed > replayMaxSpeed {
+		return fmt.Errorf("Speed must be between %g and %g", replayMinSpeed, replayMaxSpeed)
+	}
+	p := &player{
+		header: header,
+		events: events,
+		title:  header.Title,
+		speed:  speed,
+	}
+	if p.title == "" {
+		p.title = filepath.Base(path)
+	}
+	p.rewind()
+	p.layout()
+	p.play(ui.PollEvents())
+	return nil
+}
+
+// rewind goes back to the start of the recording
+func (p *player) rewind() {
+	p.screen = newVTScreen(p.header.Width, p.header.Height, nil)
+	if p.pane != nil {
+		p.pane.Screen = p.screen
+	}
+	p.next, p.clock = 0, 0
+}
+
+func (p *player) layout() {
+	x, y := ui.TerminalDimensions()
+	p.pane = newTerminalPane(p.screen)
+	p.pane.Title = fmt.Sprintf(" Replay  %s ", p.title)
+	p.pane.SetRect(0, 0, x, y-3)
+	p.status = widgets.NewParagraph()
+	p.status.TextStyle = ui.NewStyle(ui.ColorCyan)
+	p.status.SetRect(0, y-3, x, y)
+}
+
+// duration is how long the recording is, in seconds
+func (p *player) duration() float64 {
+	if len(p.events) == 0 {
+		return 0
+	}
+	return p.events[len(p.events)-1].time
+}
+
+// apply plays the next event onto the screen. Resize events resize the
+// screen, input events aren't shown.
+func (p *player) apply() {
+	event := p.events[p.next]
+	p.next++
+	p.clock = event.time
+	switch event.kind {
+	case castOutput:
+		p.screen.Write([]byte(event.data))
+	case castResize:
+		if width, height, err := castSize(event.data); err == nil {
+			p.screen.Resize(width, height)
+		}
+	}
+}
+
+// seek moves the recording to a point in time, playing it from the start
+// again when going backwards
+func (p *player) seek(to float64) {
+	if to < 0 {
+		to = 0
+	}
+	if to > p.duration() {
+		to = p.duration()
+	}
+	if to < p.clock {
+		p.rewind()
+	}
+	for p.next < len(p.events) && p.events[p.next].time <= to {
+		p.apply()
+	}
+	p.clock = to
+}
+
+func (p *player) render() {
+	state := "playing"
+	switch {
+	case p.next >= len(p.events):
+		state = "finished"
+	case p.paused:
+		state = "paused"
+	}
+	p.status.Text = fmt.Sprintf("%s / %s  %gx  %s\n%s",
+		replayClock(p.clock), replayClock(p.duration()), p.speed, state, replayHelpText)
+	ui.Render(p.pane, p.status)
+}
+
+func replayClock(seconds float64) string {
+	d := time.Duration(seconds * float64(time.Second))
+	return fmt.Sprintf("%02d:%02d.%d", int(d.Minutes()), int(d.Seconds())%60, int(d.Seconds()*10)%10)
+}
+
+// play runs the player until the user quits. The clock only moves while
+// playing, by the time spent waiting scaled by the speed.
+func (p *player) play(events <-chan ui.Event) {
+	ticker := time.NewTicker(replayTick)
+	defer ticker.Stop()
+	timer := time.NewTimer(0)
+	defer timer.Stop()
+	for {
+		p.render()
+
+		if !timer.Stop() {
+			select {
+			case <-timer.C:
+			default:
+			}
+		}
+		var due <-chan time.Time
+		playing := !p.paused && p.next < len(p.events)
+		if playing {
+			wait := (p.events[p.next].time - p.clock) / p.speed
+			timer.Reset(time.Duration(wait * float64(time.Second)))
+			due = timer.C
+		}
+		waiting := time.Now()
+		advance := func() {
+			if playing {
+				to := p.clock + time.Since(waiting).Seconds()*p.speed
+				if to > p.events[p.next].time {
+					to = p.events[p.next].time
+				}
+				p.clock = to
+			}
+		}
+
+		select {
+		case <-due:
+			// play everything due at the same moment at once
+			at := p.events[p.next].time
+			for p.next < len(p.events) && p.events[p.next].time <= at {
+				p.apply()
+			}
+
+		case <-ticker.C:
+			advance()
+
+		case e := <-events:
+			advance()
+			switch e.ID {
+			case "q", ctrlC:
+				return
+			case "<Space>":
+				p.paused = !p.paused
+			case right:
+				p.seek(p.clock + replaySeek)
+			case left:
+				p.seek(p.clock - replaySeek)
+			case home:
+				p.seek(0)
+			case end:
+				p.seek(p.duration())
+			case "+", "=":
+				if p.speed < replayMaxSpeed {
+					p.speed *= 2
+				}
+			case "-":
+				if p.speed > replayMinSpeed {
+					p.speed /= 2
+				}
+			case ".":
+				p.paused = true
+				if p.next < len(p.events) {
+					p.apply()
+				}
+			case "<Resize>":
+				ui.Clear()
+				p.layout()
+			}
+		}
+	}
+}
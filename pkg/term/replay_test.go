@@ -0,0 +1,44 @@
+package term
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+)
+
+func TestReadCastSizes(t *testing.T) {
+	for _, tc := range []struct {
+		name  string
+		cast  string
+		valid bool
+	}{
+		{"normal", `{"version": 2, "width": 80, "height": 24}
+[0.1, "o", "hello"]
+[0.2, "r", "100x30"]
+`, true},
+		{"huge header", `{"version": 2, "width": 100000, "height": 100000}
+`, false},
+		{"empty header", `{"version": 2, "width": 0, "height": 24}
+`, false},
+		{"huge resize", `{"version": 2, "width": 80, "height": 24}
+[0.1, "r", "100000x100000"]
+`, false},
+		{"bad resize", `{"version": 2, "width": 80, "height": 24}
+[0.1, "r", "wide"]
+`, false},
+	} {
+		f, err := ioutil.TempFile("", "replay")
+		if err != nil {
+			t.Fatal(err)
+		}
+		f.WriteString(tc.cast)
+		f.Close()
+		_, _, err = readCast(f.Name())
+		os.Remove(f.Name())
+		if tc.valid && err != nil {
+			t.Errorf("%s: expected the recording to be read, got %v", tc.name, err)
+		} else if !tc.valid && err == nil {
+			t.Errorf("%s: expected the recording to be refused", tc.name)
+		}
+	}
+}
@@ -0,0 +1,304 @@
+package term
+
+import (
+	"fmt"
+	"strings"
+
+	ui "github.com/gizak/termui/v3"
+	"github.com/tinyzimmer/kubeconsole/pkg/k8sutils"
+)
+
+const (
+	resourceTypesTitle   = " Resource Types "
+	resourceTypesLoading = "Discovering resource types..."
+	objectsTitle         = " Objects "
+	objectsLoading       = "Loading objects..."
+	objectsHint          = "<enter> on a resource type lists its objects"
+	manifestHint         = "<enter> on an object shows its manifest"
+)
+
+var (
+	resourceTypeColumns     = []string{"RESOURCE", "KIND"}
+	objectColumns           = []string{"NAME", "AGE"}
+	namespacedObjectColumns = []string{"NAMESPACE", "NAME", "AGE"}
+)
+
+// newResourceTypeList lists every type of object the server serves,
+// including custom resources
+func (c *controller) newResourceTypeList() (t *selectableTable) {
+	t = newSelectableTable(resourceTypeColumns...)
+	t.Title = resourceTypesTitle
+	t.TextStyle = ui.NewStyle(ui.ColorCyan)
+	x, y := ui.TerminalDimensions()
+	t.SetRect(0, 3, x/3, y-3)
+	t.Data = [][]string{{resourceTypesLoading}}
+
+	c.apiResources = nil
+	go func() {
+		resources, err := c.factory.ListAPIResources()
+		if err != nil {
+			c.errorChan <- newErrorWithStack(err)
+			return
+		}
+		c.debug(fmt.Sprintf("Discovered %d resource types", len(resources)))
+		c.apiResources = resources
+		t.Data = make([][]string, 0)
+		for _, res := range resources {
+			t.Data = append(t.Data, []string{res.String(), res.Kind})
+		}
+		if t == c.resourceTypeList && c.navWindow.ActiveTabIndex == resourcesTab {
+			c.mux.Lock()
+			ui.Render(t)
+			c.mux.Unlock()
+		}
+	}()
+	return
+}
+
+// newObjectList lists the objects of a resource type, or a hint on how to
+// get there when resource is nil. Namespaced resources are listed in the
+// current namespace, or across all of them when none is selected.
+func (c *controller) newObjectList(resource *k8sutils.APIResource) (t *selectableTable) {
+	x, y := ui.TerminalDimensions()
+	allNamespaces := resource != nil && resource.Namespaced && c.currentNamespace == ""
+	if allNamespaces {
+		t = newSelectableTable(namespacedObjectColumns...)
+	} else {
+		t = newSelectableTable(objectColumns...)
+	}
+	t.Title = objectsTitle
+	t.TextStyle = ui.NewStyle(ui.ColorCyan)
+	t.SetRect(x/3, 3, x, y/2)
+
+	c.apiResource = resource
+	c.objects = nil
+	if resource == nil {
+		t.Data = [][]string{{objectsHint}}
+		return
+	}
+	switch {
+	case !resource.Namespaced:
+		t.Title = fmt.Sprintf(" %s   %s (cluster scoped) ", objectsTitle, resource)
+	case allNamespaces:
+		t.Title = fmt.Sprintf(" %s   %s   %s ", objectsTitle, resource, allNamespacesTitle)
+	default:
+		t.Title = fmt.Sprintf(" %s   %s   Namespace: %s ", objectsTitle, resource, c.currentNamespace)
+	}
+	t.Data = [][]string{{objectsLoading}}
+
+	go func() {
+		objects, err := c.factory.ListObjects(*resource, c.currentNamespace)
+		if err != nil {
+			c.errorChan <- newErrorWithStack(err)
+			return
+		}
+		c.debug(fmt.Sprintf("Retrieved %d %s", len(objects), resource))
+		c.objects = objects
+		t.Data = make([][]string, 0)
+		for _, obj := range objects {
+			if allNamespaces {
+				t.Data = append(t.Data, []string{obj.Namespace, obj.Name, age(obj.Created)})
+			} else {
+				t.Data = append(t.Data, []string{obj.Name, age(obj.Created)})
+			}
+		}
+		if t == c.objectList && c.navWindow.ActiveTabIndex == resourcesTab {
+			c.mux.Lock()
+			ui.Render(t)
+			c.mux.Unlock()
+		}
+	}()
+	return
+}
+
+func newManifestWindow() *styledList {
+	pane := newStyledList()
+	pane.Title = manifestTitle
+	pane.Rows = plainRows([]string{manifestHint}, ui.NewStyle(ui.ColorWhite))
+	x, y := ui.TerminalDimensions()
+	pane.SetRect(x/3, y/2, x, y-3)
+	return pane
+}
+
+// renderResources renders the panes of the resource browser
+func (c *controller) renderResources() {
+	c.mux.Lock()
+	defer c.mux.Unlock()
+	ui.Render(
+		c.navWindow,
+		c.serverWindow,
+		c.helpWindow,
+		c.resourceTypeList,
+		c.objectList,
+		c.objectManifest,
+	)
+}
+
+func (c *controller) getSelectedResourceType() *k8sutils.APIResource {
+	if c.resourceTypeList == nil || c.resourceTypeList.SelectedRow >= len(c.apiResources) {
+		return nil
+	}
+	return &c.apiResources[c.resourceTypeList.SelectedRow]
+}
+
+func (c *controller) getSelectedObject() *k8sutils.ObjectSummary {
+	if c.objectList == nil || c.apiResource == nil || c.objectList.SelectedRow >= len(c.objects) {
+		return nil
+	}
+	return &c.objects[c.objectList.SelectedRow]
+}
+
+// showManifest fetches the selected object and shows it in full
+func (c *controller) showManifest() {
+	obj := c.getSelectedObject()
+	if obj == nil {
+		return
+	}
+	resource := *c.apiResource
+	pane := c.objectManifest
+	pane.Title = fmt.Sprintf(" %s   %s/%s ", manifestTitle, resource.Kind, obj.Name)
+	pane.Rows = plainRows([]string{manifestLoading}, ui.NewStyle(ui.ColorWhite))
+	pane.ScrollTop()
+	c.resourceManifest = nil
+
+	go func() {
+		res, err := c.factory.GetObject(resource, obj.Namespace, obj.Name)
+		if err != nil {
+			c.errorChan <- newErrorWithStack(err)
+			return
+		}
+		if pane != c.objectManifest {
+			return
+		}
+		c.resourceManifest = &manifest{object: res}
+		c.drawManifest(pane, c.resourceManifest)
+		if c.navWindow.ActiveTabIndex == resourcesTab {
+			c.mux.Lock()
+			ui.Render(pane)
+			c.mux.Unlock()
+		}
+	}()
+}
+
+// editSelectedObject edits the selected object in $EDITOR and shows what
+// was saved
+func (c *controller) editSelectedObject() (q string) {
+	obj := c.getSelectedObject()
+	if obj == nil {
+		return
+	}
+	updated, err := c.editObject(*c.apiResource, obj.Namespace, obj.Name)
+	if err == errLostTerminal {
+		return _quit
+	} else if err != nil {
+		c.errorChan <- newErrorWithStack(err)
+		return
+	}
+	if updated != nil {
+		c.resourceManifest = &manifest{object: updated}
+		c.drawManifest(c.objectManifest, c.resourceManifest)
+	}
+	return
+}
+
+// findResourceType moves the cursor to the first resource type whose name
+// or kind contains what the user typed
+func (c *controller) findResourceType() {
+	query := c.inputPrompt(" Find resource type ", "", nil)
+	if query == _cancel || query == _quit || query == "" {
+		return
+	}
+	query = strings.ToLower(query)
+	for idx, res := range c.apiResources {
+		if strings.Contains(res.String(), query) || strings.Contains(strings.ToLower(res.Kind), query) {
+			c.resourceTypeList.SelectedRow = idx
+			return
+		}
+	}
+	c.debug(fmt.Sprintf("No resource type matches %q", query))
+}
+
+func (c *controller) pollResources() (next int) {
+	c.focusTab(resourcesTab)
+	c.helpWindow.Text = resourcesHelpText
+	c.resourceTypeList = c.newResourceTypeList()
+	c.objectList = c.newObjectList(nil)
+	c.objectManifest = newManifestWindow()
+	c.resourceManifest = nil
+	var focus scrollable = c.resourceTypeList
+	for {
+		ui.Clear()
+		c.renderResources()
+		e := <-c.events
+		switch e.ID {
+
+		// reload, keeping the resource type that was being browsed
+		case "r":
+			c.objectList = c.newObjectList(c.apiResource)
+			c.objectManifest = newManifestWindow()
+			c.resourceManifest = nil
+			if focus == c.resourceTypeList {
+				c.resourceTypeList = c.newResourceTypeList()
+				focus = c.resourceTypeList
+			} else {
+				focus = c.objectList
+			}
+
+		case "<Tab>":
+			switch focus {
+			case c.resourceTypeList:
+				focus = c.objectList
+			case c.objectList:
+				focus = c.objectManifest
+			default:
+				focus = c.resourceTypeList
+			}
+
+		// search the manifest, or find a resource type
+		case "/":
+			if focus == c.objectManifest && c.manifestKeys(c.objectManifest, c.resourceManifest, e.ID) {
+				continue
+			}
+			c.findResourceType()
+			focus = c.resourceTypeList
+
+		// list the objects of a resource type, or show an object
+		case enter:
+			if focus == c.resourceTypeList {
+				if res := c.getSelectedResourceType(); res != nil {
+					c.objectList = c.newObjectList(res)
+					c.objectManifest = newManifestWindow()
+					c.resourceManifest = nil
+					focus = c.objectList
+				}
+			} else if focus == c.objectList {
+				c.showManifest()
+			}
+
+		// preview and apply manifests from disk
+		case "A":
+			if q := c.pollApply(); q == _quit {
+				return quitView
+			}
+
+		// edit the selected object
+		case "e":
+			if focus == c.objectList || focus == c.objectManifest {
+				if q := c.editSelectedObject(); q == _quit {
+					return quitView
+				}
+			}
+
+		default:
+			if c.manifestKeys(c.objectManifest, c.resourceManifest, e.ID) {
+				continue
+			}
+			if next, ok := c.switchView(e.ID); ok {
+				return next
+			}
+			if q := c.checkCommon(focus, e.ID); q == _quit {
+				return quitView
+			}
+		}
+	}
+}
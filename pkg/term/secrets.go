@@ -0,0 +1,338 @@
+package term
+
+import (
+	"encoding/base64"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	ui "github.com/gizak/termui/v3"
+	"github.com/tinyzimmer/kubeconsole/pkg/k8sutils"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+const (
+	secretsTitle      = " Secrets "
+	secretsLoading    = "Loading secrets..."
+	secretsNoNS       = "Select a namespace first"
+	secretKeysTitle   = " Keys "
+	secretKeysLoading = "Loading keys..."
+	secretKeysHint    = "<enter> on a secret lists its keys"
+	secretValueTitle  = " Value "
+	secretValueHint   = "<enter> on a key shows it masked, <x> reveals it"
+	secretMask        = "********"
+
+	// certificates expiring sooner than this are highlighted
+	certExpiryWarning = time.Duration(30*24) * time.Hour
+)
+
+var (
+	secretColumns    = []string{"NAME", "TYPE", "DATA", "AGE"}
+	secretKeyColumns = []string{"KEY", "SIZE"}
+)
+
+func (c *controller) newSecretList() (t *selectableTable) {
+	t = newSelectableTable(secretColumns...)
+	t.Title = fmt.Sprintf(" %s   Namespace: %s ", secretsTitle, c.currentNamespace)
+	t.TextStyle = ui.NewStyle(ui.ColorCyan)
+	x, y := ui.TerminalDimensions()
+	t.SetRect(0, 3, x/2, y/2)
+
+	c.secrets = nil
+	if c.currentNamespace == "" {
+		t.Data = [][]string{{secretsNoNS}}
+		return
+	}
+	t.Data = [][]string{{secretsLoading}}
+
+	go func() {
+		secrets, err := c.factory.ListSecrets(c.currentNamespace)
+		if err != nil {
+			c.errorChan <- newErrorWithStack(err)
+			return
+		}
+		c.debug(fmt.Sprintf("Retrieved %d secrets in %s", len(secrets), c.currentNamespace))
+		c.secrets = secrets
+		t.Data = make([][]string, 0)
+		for _, secret := range secrets {
+			t.Data = append(t.Data, []string{secret.Name, secret.Type, fmt.Sprintf("%d", secret.Keys), age(secret.Created)})
+		}
+		if c.navWindow.ActiveTabIndex == secretsTab {
+			c.mux.Lock()
+			ui.Render(t)
+			c.mux.Unlock()
+		}
+	}()
+	return
+}
+
+// newSecretKeyList lists the keys of a secret, or a hint on how to get
+// there when name is empty
+func (c *controller) newSecretKeyList(name string) (t *selectableTable) {
+	t = newSelectableTable(secretKeyColumns...)
+	t.Title = secretKeysTitle
+	t.TextStyle = ui.NewStyle(ui.ColorCyan)
+	x, y := ui.TerminalDimensions()
+	t.SetRect(x/2, 3, x, y/2)
+
+	c.secret = nil
+	c.secretKeys = nil
+	if name == "" {
+		t.Data = [][]string{{secretKeysHint}}
+		return
+	}
+	t.Title = fmt.Sprintf(" %s   Secret: %s ", secretKeysTitle, name)
+	t.Data = [][]string{{secretKeysLoading}}
+
+	go func() {
+		secret, err := c.factory.GetSecret(c.currentNamespace, name)
+		if err != nil {
+			c.errorChan <- newErrorWithStack(err)
+			return
+		}
+		keys := make([]string, 0)
+		for key := range secret.Data {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		c.secret, c.secretKeys = secret, keys
+		t.Data = make([][]string, 0)
+		for _, key := range keys {
+			t.Data = append(t.Data, []string{key, fmt.Sprintf("%d bytes", len(secret.Data[key]))})
+		}
+		if t == c.secretKeyList && c.navWindow.ActiveTabIndex == secretsTab {
+			c.mux.Lock()
+			ui.Render(t)
+			c.mux.Unlock()
+		}
+	}()
+	return
+}
+
+func newSecretValueWindow() *styledList {
+	pane := newStyledList()
+	pane.Title = secretValueTitle
+	pane.Rows = plainRows([]string{secretValueHint}, ui.NewStyle(ui.ColorWhite))
+	x, y := ui.TerminalDimensions()
+	pane.SetRect(0, y/2, x, y-3)
+	return pane
+}
+
+// renderSecrets renders the panes of the secrets view
+func (c *controller) renderSecrets() {
+	c.mux.Lock()
+	defer c.mux.Unlock()
+	ui.Render(
+		c.navWindow,
+		c.serverWindow,
+		c.helpWindow,
+		c.secretList,
+		c.secretKeyList,
+		c.secretValue,
+	)
+}
+
+func (c *controller) getSelectedSecret() string {
+	if c.secretList == nil || c.secretList.SelectedRow >= len(c.secrets) {
+		return ""
+	}
+	return c.secrets[c.secretList.SelectedRow].Name
+}
+
+func (c *controller) getSelectedSecretKey() string {
+	if c.secretKeyList == nil || c.secret == nil || c.secretKeyList.SelectedRow >= len(c.secretKeys) {
+		return ""
+	}
+	return c.secretKeys[c.secretKeyList.SelectedRow]
+}
+
+// showSecretValue shows what can be shown of the selected key without
+// revealing it. Certificates are public, so they are decoded and
+// summarized, and docker configs are reduced to the registries they hold
+// credentials for. Anything else is masked.
+func (c *controller) showSecretValue() {
+	key := c.getSelectedSecretKey()
+	if key == "" {
+		return
+	}
+	c.secretValue.Title = fmt.Sprintf(" %s   %s ", secretValueTitle, key)
+	c.secretValue.ScrollTop()
+	white := ui.NewStyle(ui.ColorWhite)
+
+	switch {
+	case k8sutils.IsCertificateKey(c.secret, key):
+		certs, err := k8sutils.ParseCertificates(c.secret.Data[key])
+		if err != nil {
+			c.secretValue.Rows = plainRows([]string{err.Error()}, ui.NewStyle(ui.ColorRed))
+			return
+		}
+		c.secretValue.Rows = certificateRows(certs)
+
+	case k8sutils.IsDockerConfigKey(c.secret, key):
+		registries, err := k8sutils.DockerConfigRegistries(c.secret, key)
+		if err != nil {
+			c.secretValue.Rows = plainRows([]string{err.Error()}, ui.NewStyle(ui.ColorRed))
+			return
+		}
+		lines := []string{"Credentials for registries:"}
+		for _, registry := range registries {
+			lines = append(lines, fmt.Sprintf("   %s", registry))
+		}
+		c.secretValue.Rows = plainRows(lines, white)
+
+	default:
+		c.secretValue.Rows = plainRows([]string{
+			fmt.Sprintf("%s (%d bytes)", secretMask, len(c.secret.Data[key])),
+		}, white)
+	}
+}
+
+// revealSecretValue shows the decoded value of the selected key, leaving
+// an audit line behind
+func (c *controller) revealSecretValue() {
+	key := c.getSelectedSecretKey()
+	if key == "" {
+		return
+	}
+	c.audit(fmt.Sprintf("%q revealed key %s of secret %s/%s", c.user, key, c.secret.Namespace, c.secret.Name))
+	c.secretValue.Title = fmt.Sprintf(" %s   %s (revealed) ", secretValueTitle, key)
+	c.secretValue.Rows = plainRows(strings.Split(string(c.secret.Data[key]), "\n"), ui.NewStyle(ui.ColorYellow))
+	c.secretValue.ScrollTop()
+}
+
+// maskSecretData masks the values of a core/v1 Secret in place, so that
+// manifests and diffs don't reveal what this view only shows with an audit
+// line. kubectl apply keeps a copy of the values in an annotation, which is
+// masked too. When previous is given, values that differ from it are
+// marked as changed.
+func maskSecretData(obj, previous *unstructured.Unstructured) {
+	if obj.GetAPIVersion() != "v1" || obj.GetKind() != "Secret" {
+		return
+	}
+	changed := func(field, key string, value interface{}) string {
+		if previous == nil {
+			return ""
+		}
+		if old, _ := previous.Object[field].(map[string]interface{}); old[key] != value {
+			return ", changed"
+		}
+		return ""
+	}
+	if annotations := obj.GetAnnotations(); annotations[corev1.LastAppliedConfigAnnotation] != "" {
+		mask := secretMask
+		if previous != nil && previous.GetAnnotations()[corev1.LastAppliedConfigAnnotation] != annotations[corev1.LastAppliedConfigAnnotation] {
+			mask = fmt.Sprintf("%s (changed)", secretMask)
+		}
+		annotations[corev1.LastAppliedConfigAnnotation] = mask
+		obj.SetAnnotations(annotations)
+	}
+	for _, field := range []string{"data", "stringData"} {
+		values, ok := obj.Object[field].(map[string]interface{})
+		if !ok {
+			continue
+		}
+		for key, value := range values {
+			text, _ := value.(string)
+			size := len(text)
+			if decoded, err := base64.StdEncoding.DecodeString(text); err == nil && field == "data" {
+				size = len(decoded)
+			}
+			values[key] = fmt.Sprintf("%s (%d bytes%s)", secretMask, size, changed(field, key, value))
+		}
+	}
+}
+
+// certificateRows summarizes each certificate in a bundle, colouring
+// certificates that have expired red and those expiring soon yellow
+func certificateRows(certs []k8sutils.CertificateSummary) [][]span {
+	rows := make([][]span, 0)
+	white := ui.NewStyle(ui.ColorWhite)
+	for idx, cert := range certs {
+		if idx > 0 {
+			rows = append(rows, []span{})
+		}
+		expiryStyle := ui.NewStyle(ui.ColorGreen)
+		expiry := fmt.Sprintf("Not After:  %s (expires in %s)", cert.NotAfter, time.Until(cert.NotAfter).Round(time.Hour))
+		if cert.Expired() {
+			expiryStyle = ui.NewStyle(ui.ColorRed)
+			expiry = fmt.Sprintf("Not After:  %s (EXPIRED %s ago)", cert.NotAfter, time.Since(cert.NotAfter).Round(time.Hour))
+		} else if time.Until(cert.NotAfter) < certExpiryWarning {
+			expiryStyle = ui.NewStyle(ui.ColorYellow)
+		}
+		sans := append(append([]string{}, cert.DNSNames...), cert.IPAddresses...)
+		if len(sans) == 0 {
+			sans = []string{"<none>"}
+		}
+		rows = append(rows,
+			[]span{{text: fmt.Sprintf("Certificate %d of %d", idx+1, len(certs)), style: ui.NewStyle(ui.ColorCyan)}},
+			[]span{{text: fmt.Sprintf("Subject:    %s", cert.Subject), style: white}},
+			[]span{{text: fmt.Sprintf("Issuer:     %s", cert.Issuer), style: white}},
+			[]span{{text: fmt.Sprintf("SANs:       %s", strings.Join(sans, ", ")), style: white}},
+			[]span{{text: fmt.Sprintf("CA:         %t", cert.IsCA), style: white}},
+			[]span{{text: fmt.Sprintf("Not Before: %s", cert.NotBefore), style: white}},
+			[]span{{text: expiry, style: expiryStyle}},
+		)
+	}
+	return rows
+}
+
+func (c *controller) pollSecrets() (next int) {
+	c.focusTab(secretsTab)
+	c.helpWindow.Text = secretsHelpText
+	c.secretList = c.newSecretList()
+	c.secretKeyList = c.newSecretKeyList("")
+	c.secretValue = newSecretValueWindow()
+	var focus scrollable = c.secretList
+	for {
+		ui.Clear()
+		c.renderSecrets()
+		e := <-c.events
+		switch e.ID {
+
+		// reload
+		case "r":
+			c.secretList = c.newSecretList()
+			c.secretKeyList = c.newSecretKeyList("")
+			c.secretValue = newSecretValueWindow()
+			focus = c.secretList
+
+		case "<Tab>":
+			switch focus {
+			case c.secretList:
+				focus = c.secretKeyList
+			case c.secretKeyList:
+				focus = c.secretValue
+			default:
+				focus = c.secretList
+			}
+
+		// list the keys of a secret, or show a key masked
+		case enter:
+			if focus == c.secretList {
+				if name := c.getSelectedSecret(); name != "" {
+					c.secretKeyList = c.newSecretKeyList(name)
+					c.secretValue = newSecretValueWindow()
+					focus = c.secretKeyList
+				}
+			} else if focus == c.secretKeyList {
+				c.showSecretValue()
+			}
+
+		// reveal the selected key
+		case "x":
+			if focus == c.secretKeyList || focus == c.secretValue {
+				c.revealSecretValue()
+			}
+
+		default:
+			if next, ok := c.switchView(e.ID); ok {
+				return next
+			}
+			if q := c.checkCommon(focus, e.ID); q == _quit {
+				return quitView
+			}
+		}
+	}
+}
@@ -0,0 +1,63 @@
+package term
+
+import (
+	"strings"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func TestMaskSecretData(t *testing.T) {
+	secret := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "v1",
+		"kind":       "Secret",
+		"metadata": map[string]interface{}{
+			"annotations": map[string]interface{}{
+				corev1.LastAppliedConfigAnnotation: `{"data":{"password":"aHVudGVyMg=="}}`,
+			},
+		},
+		"data":       map[string]interface{}{"password": "aHVudGVyMg=="},
+		"stringData": map[string]interface{}{"token": "abc"},
+	}}
+	maskSecretData(secret, nil)
+	if got := secret.Object["data"].(map[string]interface{})["password"]; got != secretMask+" (7 bytes)" {
+		t.Errorf("expected data to be masked, got %v", got)
+	}
+	if got := secret.GetAnnotations()[corev1.LastAppliedConfigAnnotation]; got != secretMask {
+		t.Errorf("expected the last applied annotation to be masked, got %v", got)
+	}
+	if got := secret.Object["stringData"].(map[string]interface{})["token"]; got != secretMask+" (3 bytes)" {
+		t.Errorf("expected stringData to be masked, got %v", got)
+	}
+
+	configMap := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "v1",
+		"kind":       "ConfigMap",
+		"data":       map[string]interface{}{"key": "value"},
+	}}
+	maskSecretData(configMap, nil)
+	if got := configMap.Object["data"].(map[string]interface{})["key"]; strings.Contains(got.(string), secretMask) {
+		t.Errorf("expected other kinds to be left alone, got %v", got)
+	}
+}
+
+func TestMaskSecretDataChanges(t *testing.T) {
+	secret := func(password, token string) *unstructured.Unstructured {
+		return &unstructured.Unstructured{Object: map[string]interface{}{
+			"apiVersion": "v1",
+			"kind":       "Secret",
+			"data":       map[string]interface{}{"password": password, "token": token},
+		}}
+	}
+	before := secret("aHVudGVyMg==", "YWJj")
+	after := secret("aHVudGVyMw==", "YWJj")
+	maskSecretData(after, before)
+	data := after.Object["data"].(map[string]interface{})
+	if got := data["password"]; got != secretMask+" (7 bytes, changed)" {
+		t.Errorf("expected the changed value to be marked, got %v", got)
+	}
+	if got := data["token"]; got != secretMask+" (3 bytes)" {
+		t.Errorf("expected the unchanged value to be masked only, got %v", got)
+	}
+}
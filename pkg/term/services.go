@@ -0,0 +1,279 @@
+package term
+
+import (
+	"fmt"
+
+	ui "github.com/gizak/termui/v3"
+	"github.com/tinyzimmer/kubeconsole/pkg/k8sutils"
+)
+
+const (
+	servicesTitle    = " Services "
+	servicesLoading  = "Loading services..."
+	servicesNoNS     = "Select a namespace first"
+	endpointsTitle   = " Endpoints "
+	endpointsLoading = "Loading endpoints..."
+	endpointsHint    = "<enter> on a service lists its endpoints"
+	endpointsNone    = "No endpoints, check the selector matches ready pods"
+	noSelector       = "<none>"
+)
+
+var (
+	serviceColumns  = []string{"NAME", "TYPE", "CLUSTER-IP", "EXTERNAL-IP", "PORT(S)", "SELECTOR", "AGE"}
+	endpointColumns = []string{"ADDRESS", "READY", "POD", "NODE", "PORTS"}
+)
+
+func (c *controller) newServiceList() (t *selectableTable) {
+	t = newSelectableTable(serviceColumns...)
+	t.Title = fmt.Sprintf(" %s   Namespace: %s ", servicesTitle, c.currentNamespace)
+	t.TextStyle = ui.NewStyle(ui.ColorCyan)
+	x, y := ui.TerminalDimensions()
+	t.SetRect(0, 3, x, y/2)
+
+	c.services = nil
+	// another view may have asked for a service to be selected
+	selectName := c.serviceToSelect
+	c.serviceToSelect = ""
+	if c.currentNamespace == "" {
+		t.Data = [][]string{{servicesNoNS}}
+		return
+	}
+	t.Data = [][]string{{servicesLoading}}
+
+	go func() {
+		services, err := c.factory.ListServices(c.currentNamespace)
+		if err != nil {
+			c.errorChan <- newErrorWithStack(err)
+			return
+		}
+		c.debug(fmt.Sprintf("Retrieved %d services in %s", len(services), c.currentNamespace))
+		c.services = services
+		t.Data, t.DataStyles = serviceRows(services)
+		for idx, svc := range services {
+			if svc.Name == selectName {
+				t.SelectedRow = idx
+			}
+		}
+		if c.navWindow.ActiveTabIndex == servicesTab {
+			c.mux.Lock()
+			ui.Render(t)
+			c.mux.Unlock()
+		}
+	}()
+	return
+}
+
+// newEndpointList lists the addresses behind a service, or a hint on
+// how to get there when service is empty
+func (c *controller) newEndpointList(service string) (t *selectableTable) {
+	t = newSelectableTable(endpointColumns...)
+	t.Title = endpointsTitle
+	t.TextStyle = ui.NewStyle(ui.ColorCyan)
+	x, y := ui.TerminalDimensions()
+	t.SetRect(0, y/2, x, y-3)
+
+	c.endpoints = nil
+	if service == "" {
+		t.Data = [][]string{{endpointsHint}}
+		return
+	}
+	t.Title = fmt.Sprintf(" %s   Service: %s ", endpointsTitle, service)
+	t.Data = [][]string{{endpointsLoading}}
+
+	go func() {
+		endpoints, err := c.factory.ListEndpoints(c.currentNamespace, service)
+		if err != nil {
+			c.errorChan <- newErrorWithStack(err)
+			return
+		}
+		c.debug(fmt.Sprintf("Retrieved %d endpoint addresses for %s", len(endpoints), service))
+		c.endpoints = endpoints
+		if len(endpoints) == 0 {
+			t.Data = [][]string{{endpointsNone}}
+			t.DataStyles = map[int]ui.Style{0: ui.NewStyle(ui.ColorYellow)}
+		} else {
+			t.Data, t.DataStyles = endpointRows(endpoints)
+		}
+		if t == c.endpointList && c.navWindow.ActiveTabIndex == servicesTab {
+			c.mux.Lock()
+			ui.Render(t)
+			c.mux.Unlock()
+		}
+	}()
+	return
+}
+
+// serviceRows formats service summaries into table rows, colouring load
+// balancers still waiting for an address
+func serviceRows(services []k8sutils.ServiceSummary) (rows [][]string, styles map[int]ui.Style) {
+	rows = make([][]string, 0)
+	styles = make(map[int]ui.Style)
+	for idx, svc := range services {
+		rows = append(rows, []string{
+			svc.Name,
+			svc.Type,
+			svc.ClusterIP,
+			svc.ExternalIPs,
+			svc.Ports,
+			svc.Selector,
+			age(svc.Created),
+		})
+		if svc.ExternalIPs == "<pending>" {
+			styles[idx] = ui.NewStyle(ui.ColorYellow)
+		}
+	}
+	return
+}
+
+// endpointRows formats endpoint addresses into table rows, colouring the
+// addresses that aren't ready
+func endpointRows(endpoints []k8sutils.EndpointAddress) (rows [][]string, styles map[int]ui.Style) {
+	rows = make([][]string, 0)
+	styles = make(map[int]ui.Style)
+	for idx, ep := range endpoints {
+		ready := "true"
+		if !ep.Ready {
+			ready = "false"
+			styles[idx] = ui.NewStyle(ui.ColorRed)
+		}
+		pod := ep.Pod
+		if pod == "" {
+			pod = "<none>"
+		}
+		rows = append(rows, []string{ep.IP, ready, pod, ep.Node, ep.Ports})
+	}
+	return
+}
+
+// renderServices renders the panes of the services view
+func (c *controller) renderServices() {
+	c.mux.Lock()
+	defer c.mux.Unlock()
+	ui.Render(
+		c.navWindow,
+		c.serverWindow,
+		c.helpWindow,
+		c.serviceList,
+		c.endpointList,
+	)
+}
+
+func (c *controller) getSelectedService() *k8sutils.ServiceSummary {
+	if c.serviceList == nil || c.serviceList.SelectedRow >= len(c.services) {
+		return nil
+	}
+	return &c.services[c.serviceList.SelectedRow]
+}
+
+func (c *controller) getSelectedEndpoint() *k8sutils.EndpointAddress {
+	if c.endpointList == nil || c.endpointList.SelectedRow >= len(c.endpoints) {
+		return nil
+	}
+	return &c.endpoints[c.endpointList.SelectedRow]
+}
+
+// filterPodsByService points the pod list at the pods matching the
+// selected service's selector
+func (c *controller) filterPodsByService() bool {
+	svc := c.getSelectedService()
+	if svc == nil {
+		return false
+	}
+	if svc.Selector == noSelector {
+		c.errorChan <- newErrorWithStack(fmt.Errorf("Service %s has no selector, its endpoints are managed by hand", svc.Name))
+		return false
+	}
+	c.debug(fmt.Sprintf("Filtering pods by service %s selector: %s", svc.Name, svc.Selector))
+	c.podFilter = k8sutils.PodFilter{LabelSelector: svc.Selector}
+	ch := make(chan string)
+	c.podList = c.newPodList(ch)
+	ch <- c.currentNamespace
+	return true
+}
+
+// jumpToEndpointPod opens the pod pane with the pod behind the selected
+// endpoint address selected
+func (c *controller) jumpToEndpointPod() bool {
+	ep := c.getSelectedEndpoint()
+	if ep == nil {
+		return false
+	}
+	if ep.Pod == "" {
+		c.errorChan <- newErrorWithStack(fmt.Errorf("Endpoint %s isn't backed by a pod", ep.IP))
+		return false
+	}
+	c.debug(fmt.Sprintf("Jumping to pod %s behind endpoint %s", ep.Pod, ep.IP))
+	c.podFilter = k8sutils.PodFilter{}
+	c.podToSelect = ep.Pod
+	ch := make(chan string)
+	c.podList = c.newPodList(ch)
+	ch <- c.currentNamespace
+	return true
+}
+
+func (c *controller) pollServices() (next int) {
+	c.focusTab(servicesTab)
+	c.helpWindow.Text = servicesHelpText
+	jumpTo := c.serviceToSelect
+	c.serviceList = c.newServiceList()
+	c.endpointList = c.newEndpointList(jumpTo)
+	var focus scrollable = c.serviceList
+	for {
+		ui.Clear()
+		c.renderServices()
+		e := <-c.events
+		switch e.ID {
+
+		// reload
+		case "r":
+			c.serviceList = c.newServiceList()
+			c.endpointList = c.newEndpointList("")
+			focus = c.serviceList
+
+		// forward ports to the selected service
+		case "F":
+			if svc := c.getSelectedService(); svc != nil {
+				q := c.promptForward(svc.Namespace, svc.Name, true)
+				if q == _quit {
+					return quitView
+				} else if q == _cancel {
+					continue
+				}
+			}
+			return forwardsTab
+
+		case "<Tab>":
+			if focus == c.serviceList {
+				focus = c.endpointList
+			} else {
+				focus = c.serviceList
+			}
+
+		// list the endpoints of a service, or jump to the pod behind an
+		// endpoint
+		case enter:
+			if focus == c.serviceList {
+				if svc := c.getSelectedService(); svc != nil {
+					c.endpointList = c.newEndpointList(svc.Name)
+					focus = c.endpointList
+				}
+			} else if c.jumpToEndpointPod() {
+				return podsTab
+			}
+
+		// show the pods matching the service selector
+		case "f":
+			if c.filterPodsByService() {
+				return podsTab
+			}
+
+		default:
+			if next, ok := c.switchView(e.ID); ok {
+				return next
+			}
+			if q := c.checkCommon(focus, e.ID); q == _quit {
+				return quitView
+			}
+		}
+	}
+}
@@ -0,0 +1,326 @@
+package term
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	ui "github.com/gizak/termui/v3"
+	"github.com/gizak/termui/v3/widgets"
+)
+
+const (
+	sessionsTitle = " Sessions "
+	sessionsNone  = "No sessions. <t> tails the logs of the selected pod, <e> execs into it"
+	logEnded      = "ended"
+	logPaused     = "PAUSED: Press <enter> to resume"
+	execDetach    = "Ctrl-] to detach"
+
+	// how many lines a log tail keeps
+	logHistory = 5000
+
+	// Ctrl-] leaves an exec session running in the background, like
+	// telnet
+	detachKey = "<C-5>"
+)
+
+type sessionKind int
+
+const (
+	logSession sessionKind = iota
+	execSession
+)
+
+// session is a log tail or exec shell in a tab under the pods view. It
+// keeps running in the background until it is closed or ends, whichever
+// tab is showing and whatever view is in front.
+type session struct {
+	kind   sessionKind
+	name   string
+	cancel func()
+	ended  bool
+
+	// log tails
+	logs    *widgets.List
+	partial string
+	paused  bool
+
+	// exec shells
+	term  *terminalPane
+	stdin io.Writer
+	done  chan struct{}
+}
+
+// pane is what the session shows
+func (s *session) pane() scrollable {
+	if s.kind == execSession {
+		return s.term
+	}
+	return s.logs
+}
+
+func (s *session) title() string {
+	if s.kind == execSession {
+		return fmt.Sprintf(" Exec  %s  %s ", s.name, execDetach)
+	}
+	title := fmt.Sprintf(" Logs  %s ", s.name)
+	if s.ended {
+		title = fmt.Sprintf("%s (%s) ", title, logEnded)
+	}
+	if s.paused {
+		title = fmt.Sprintf("%s  %s ", title, logPaused)
+	}
+	return title
+}
+
+// tabName is the name of the session in the tab strip
+func (s *session) tabName() string {
+	if s.kind == execSession {
+		return fmt.Sprintf("exec %s", s.name)
+	}
+	if s.ended {
+		return fmt.Sprintf("logs %s (%s)", s.name, logEnded)
+	}
+	return fmt.Sprintf("logs %s", s.name)
+}
+
+// appendLogs adds streamed output to a log tail, holding back the last
+// line until it is complete
+func (s *session) appendLogs(out string) {
+	lines := strings.Split(s.partial+strings.Replace(out, "\r\n", "\n", -1), "\n")
+	s.partial = lines[len(lines)-1]
+	s.logs.Rows = append(s.logs.Rows, lines[:len(lines)-1]...)
+	if extra := len(s.logs.Rows) - logHistory; extra > 0 {
+		s.logs.Rows = s.logs.Rows[extra:]
+		s.logs.SelectedRow -= extra
+		if s.logs.SelectedRow < 0 {
+			s.logs.SelectedRow = 0
+		}
+	}
+	if !s.paused {
+		s.logs.ScrollBottom()
+	}
+}
+
+// sessionRect is where sessions are shown, under the strip of their tabs
+func sessionRect() (x1, y1, x2, y2 int) {
+	x, y := ui.TerminalDimensions()
+	return 0, y/2 + 1, x, y - 3
+}
+
+func newSessionTabs() *widgets.TabPane {
+	tabs := widgets.NewTabPane()
+	tabs.Border = false
+	x, y := ui.TerminalDimensions()
+	tabs.SetRect(0, y/2, x, y/2+1)
+	return tabs
+}
+
+// newNoSessionsWindow is shown in place of sessions when there are none
+func newNoSessionsWindow() *widgets.List {
+	pane := widgets.NewList()
+	pane.Title = sessionsTitle
+	pane.TextStyle = ui.NewStyle(ui.ColorWhite)
+	pane.SelectedRowStyle = ui.NewStyle(ui.ColorWhite)
+	pane.Rows = []string{sessionsNone}
+	pane.SetRect(sessionRect())
+	return pane
+}
+
+// activeSession returns the session whose tab is showing, or nil
+func (c *controller) activeSession() *session {
+	if c.sessionTabs.ActiveTabIndex >= len(c.sessions) {
+		return nil
+	}
+	return c.sessions[c.sessionTabs.ActiveTabIndex]
+}
+
+// sessionPane returns the pane of the session whose tab is showing
+func (c *controller) sessionPane() scrollable {
+	if s := c.activeSession(); s != nil {
+		return s.pane()
+	}
+	return c.noSessions
+}
+
+// updateSessionTabs names the tabs after the sessions and titles the
+// pane that is showing. Call with c.mux held.
+func (c *controller) updateSessionTabs() {
+	names := make([]string, 0)
+	for _, s := range c.sessions {
+		names = append(names, s.tabName())
+	}
+	c.sessionTabs.TabNames = names
+	if c.sessionTabs.ActiveTabIndex >= len(names) {
+		c.sessionTabs.ActiveTabIndex = 0
+		if len(names) > 0 {
+			c.sessionTabs.ActiveTabIndex = len(names) - 1
+		}
+	}
+	title := sessionsTitle
+	if s := c.activeSession(); s != nil {
+		title = s.title()
+	}
+	pane := c.sessionPane()
+	if focus == pane {
+		title = fmt.Sprintf(" * %s ", title)
+	}
+	blockOf(pane).Title = title
+}
+
+// addSession opens a tab for a session and shows it
+func (c *controller) addSession(s *session) {
+	c.debug(fmt.Sprintf("Opening session %s", s.tabName()))
+	c.mux.Lock()
+	defer c.mux.Unlock()
+	focused := focus == c.sessionPane()
+	c.sessions = append(c.sessions, s)
+	c.sessionTabs.ActiveTabIndex = len(c.sessions) - 1
+	if focused {
+		focus = s.pane()
+	}
+	c.updateSessionTabs()
+}
+
+// removeSession stops a session and closes its tab. Sessions already
+// removed are ignored.
+func (c *controller) removeSession(s *session) {
+	c.mux.Lock()
+	removed := false
+	for idx := range c.sessions {
+		if c.sessions[idx] != s {
+			continue
+		}
+		s.cancel()
+		c.sessions = append(c.sessions[:idx], c.sessions[idx+1:]...)
+		if idx < c.sessionTabs.ActiveTabIndex {
+			c.sessionTabs.ActiveTabIndex--
+		}
+		if focus == s.pane() {
+			focus = c.sessionPane()
+		}
+		c.updateSessionTabs()
+		c.renderSessions()
+		removed = true
+		break
+	}
+	c.mux.Unlock()
+	if removed {
+		c.debug(fmt.Sprintf("Closed session %s", s.tabName()))
+	}
+}
+
+// closeSession closes the session whose tab is showing, asking first when
+// it is a shell
+func (c *controller) closeSession() (q string) {
+	s := c.activeSession()
+	if s == nil {
+		return
+	}
+	if s.kind == execSession {
+		if q = c.confirmPrompt(fmt.Sprintf(" Close the shell in %s? ", s.name)); q != confirmYes {
+			return
+		}
+	}
+	c.removeSession(s)
+	return ""
+}
+
+// switchSession shows the next or previous session
+func (c *controller) switchSession(step int) {
+	c.mux.Lock()
+	defer c.mux.Unlock()
+	if len(c.sessions) == 0 {
+		return
+	}
+	focused := focus == c.sessionPane()
+	c.sessionTabs.ActiveTabIndex = (c.sessionTabs.ActiveTabIndex + step + len(c.sessions)) % len(c.sessions)
+	if focused {
+		focus = c.sessionPane()
+	}
+	c.updateSessionTabs()
+}
+
+// showSession shows a session and gives it focus
+func (c *controller) showSession(s *session) {
+	c.mux.Lock()
+	defer c.mux.Unlock()
+	for idx := range c.sessions {
+		if c.sessions[idx] == s {
+			c.sessionTabs.ActiveTabIndex = idx
+			focus = s.pane()
+		}
+	}
+	c.updateSessionTabs()
+}
+
+// renderSession draws a session when it is showing
+func (c *controller) renderSession(s *session) {
+	c.mux.Lock()
+	defer c.mux.Unlock()
+	if c.activeSession() == s {
+		c.renderSessions()
+	}
+}
+
+// renderSessions draws the sessions pane when the pods view is in front.
+// Call with c.mux held.
+func (c *controller) renderSessions() {
+	if c.navWindow.ActiveTabIndex == podsTab {
+		ui.Render(c.sessionTabs, c.sessionPane())
+	}
+}
+
+// pauseLogSession stops a log tail from following new lines while it is
+// being scrolled through
+func (c *controller) pauseLogSession() {
+	c.mux.Lock()
+	defer c.mux.Unlock()
+	s := c.activeSession()
+	if s == nil || s.kind != logSession || focus != s.logs || s.paused {
+		return
+	}
+	s.paused = true
+	c.updateSessionTabs()
+}
+
+// resumeSession follows a paused log tail again, or attaches to a shell
+// until it is detached from
+func (c *controller) resumeSession() {
+	s := c.activeSession()
+	if s == nil {
+		return
+	}
+	if s.kind == execSession {
+		c.pollExecutor(s)
+		return
+	}
+	c.mux.Lock()
+	defer c.mux.Unlock()
+	s.paused = false
+	s.logs.ScrollBottom()
+	c.updateSessionTabs()
+}
+
+// resizeSessions lays the sessions out again for the terminal size
+func (c *controller) resizeSessions() {
+	c.mux.Lock()
+	defer c.mux.Unlock()
+	active := c.sessionTabs.ActiveTabIndex
+	c.sessionTabs = newSessionTabs()
+	c.sessionTabs.ActiveTabIndex = active
+	focused := focus == c.noSessions
+	c.noSessions = newNoSessionsWindow()
+	if focused {
+		focus = c.noSessions
+	}
+	for _, s := range c.sessions {
+		if s.kind == execSession {
+			s.term.SetRect(sessionRect())
+			s.term.Fit()
+			continue
+		}
+		s.logs.SetRect(sessionRect())
+	}
+	c.updateSessionTabs()
+}
@@ -0,0 +1,124 @@
+package term
+
+import (
+	"image"
+
+	ui "github.com/gizak/termui/v3"
+	rw "github.com/mattn/go-runewidth"
+)
+
+// span is a run of text drawn in a single style
+type span struct {
+	text  string
+	style ui.Style
+}
+
+// styledList is a scrollable, read-only list whose rows are built from
+// styled spans instead of termui's [text](style) markup, so documents
+// full of brackets (YAML, diffs) are drawn as they are
+type styledList struct {
+	ui.Block
+
+	Rows      [][]span
+	TextStyle ui.Style
+
+	topRow int
+}
+
+func newStyledList() *styledList {
+	return &styledList{
+		Block:     *ui.NewBlock(),
+		Rows:      [][]span{},
+		TextStyle: ui.NewStyle(ui.ColorWhite),
+	}
+}
+
+// plainRows turns lines of text into rows of a single span
+func plainRows(lines []string, style ui.Style) [][]span {
+	rows := make([][]span, 0)
+	for _, line := range lines {
+		rows = append(rows, []span{{text: line, style: style}})
+	}
+	return rows
+}
+
+func (l *styledList) Draw(buf *ui.Buffer) {
+	l.Block.Draw(buf)
+	l.ScrollAmount(0)
+
+	y := l.Inner.Min.Y
+	for row := l.topRow; row < len(l.Rows) && y < l.Inner.Max.Y; row++ {
+		x := l.Inner.Min.X
+	spans:
+		for _, s := range l.Rows[row] {
+			for _, r := range s.text {
+				if r == '\t' {
+					r = ' '
+				}
+				if x+rw.RuneWidth(r) > l.Inner.Max.X {
+					buf.SetCell(ui.NewCell(ui.ELLIPSES, s.style), image.Pt(l.Inner.Max.X-1, y))
+					break spans
+				}
+				buf.SetCell(ui.NewCell(r, s.style), image.Pt(x, y))
+				x += rw.RuneWidth(r)
+			}
+		}
+		y++
+	}
+
+	if l.topRow > 0 {
+		buf.SetCell(
+			ui.NewCell(ui.UP_ARROW, ui.NewStyle(ui.ColorWhite)),
+			image.Pt(l.Inner.Max.X-1, l.Inner.Min.Y),
+		)
+	}
+	if len(l.Rows) > l.topRow+l.Inner.Dy() {
+		buf.SetCell(
+			ui.NewCell(ui.DOWN_ARROW, ui.NewStyle(ui.ColorWhite)),
+			image.Pt(l.Inner.Max.X-1, l.Inner.Max.Y-1),
+		)
+	}
+}
+
+// ScrollAmount moves the view by a number of rows, keeping the last page
+// full
+func (l *styledList) ScrollAmount(amount int) {
+	l.topRow += amount
+	if max := len(l.Rows) - l.Inner.Dy(); l.topRow > max {
+		l.topRow = max
+	}
+	if l.topRow < 0 {
+		l.topRow = 0
+	}
+}
+
+func (l *styledList) ScrollUp() {
+	l.ScrollAmount(-1)
+}
+
+func (l *styledList) ScrollDown() {
+	l.ScrollAmount(1)
+}
+
+func (l *styledList) ScrollPageUp() {
+	l.ScrollAmount(-l.Inner.Dy())
+}
+
+func (l *styledList) ScrollPageDown() {
+	l.ScrollAmount(l.Inner.Dy())
+}
+
+func (l *styledList) ScrollTop() {
+	l.topRow = 0
+}
+
+func (l *styledList) ScrollBottom() {
+	l.ScrollAmount(len(l.Rows))
+}
+
+// ScrollTo brings a row to the top of the view, or as near to it as the
+// last page allows
+func (l *styledList) ScrollTo(row int) {
+	l.topRow = row
+	l.ScrollAmount(0)
+}
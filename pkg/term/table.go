@@ -0,0 +1,143 @@
+package term
+
+import (
+	ui "github.com/gizak/termui/v3"
+	"github.com/gizak/termui/v3/widgets"
+)
+
+// selectableTable is a widgets.Table with a header, a cursor and scrolling,
+// so that it can take focus and be scrolled the same as a widgets.List
+type selectableTable struct {
+	widgets.Table
+
+	Header           []string
+	Data             [][]string
+	DataStyles       map[int]ui.Style
+	SelectedRow      int
+	SelectedRowStyle ui.Style
+	HeaderStyle      ui.Style
+
+	topRow int
+}
+
+func newSelectableTable(header ...string) *selectableTable {
+	t := &selectableTable{
+		Table:            *widgets.NewTable(),
+		Header:           header,
+		Data:             [][]string{},
+		DataStyles:       make(map[int]ui.Style),
+		SelectedRowStyle: ui.NewStyle(ui.ColorYellow),
+		HeaderStyle:      ui.NewStyle(ui.ColorWhite, ui.ColorClear, ui.ModifierBold),
+	}
+	t.RowSeparator = false
+	t.TextAlignment = ui.AlignLeft
+	return t
+}
+
+func (t *selectableTable) Draw(buf *ui.Buffer) {
+	// header takes the first line
+	height := t.Inner.Dy() - 1
+	if height < 1 {
+		height = 1
+	}
+	if t.SelectedRow >= len(t.Data) {
+		t.SelectedRow = len(t.Data) - 1
+	}
+	if t.SelectedRow < 0 {
+		t.SelectedRow = 0
+	}
+	if t.SelectedRow < t.topRow {
+		t.topRow = t.SelectedRow
+	} else if t.SelectedRow >= t.topRow+height {
+		t.topRow = t.SelectedRow - height + 1
+	}
+
+	bottom := t.topRow + height
+	if bottom > len(t.Data) {
+		bottom = len(t.Data)
+	}
+	t.Rows = append([][]string{t.Header}, t.Data[t.topRow:bottom]...)
+	t.RowStyles = map[int]ui.Style{0: t.HeaderStyle}
+	for idx := t.topRow; idx < bottom; idx++ {
+		if style, ok := t.DataStyles[idx]; ok {
+			t.RowStyles[idx-t.topRow+1] = style
+		}
+	}
+	if len(t.Data) > 0 {
+		t.RowStyles[t.SelectedRow-t.topRow+1] = t.SelectedRowStyle
+	}
+	t.ColumnWidths = fitColumns(t.Rows, t.Inner.Dx())
+	t.Table.Draw(buf)
+}
+
+// fitColumns sizes each column to its widest cell, then takes space from
+// the widest column until the table (with separators) fits the width
+func fitColumns(rows [][]string, width int) []int {
+	widths := make([]int, len(rows[0]))
+	for _, row := range rows {
+		for idx, cell := range row {
+			if idx < len(widths) && len(cell)+1 > widths[idx] {
+				widths[idx] = len(cell) + 1
+			}
+		}
+	}
+	for {
+		total := len(widths) - 1
+		widest := 0
+		for idx, w := range widths {
+			total += w
+			if w > widths[widest] {
+				widest = idx
+			}
+		}
+		if total <= width || widths[widest] <= 1 {
+			return widths
+		}
+		widths[widest]--
+	}
+}
+
+func (t *selectableTable) ScrollAmount(amount int) {
+	if len(t.Data) == 0 {
+		return
+	}
+	t.SelectedRow += amount
+	if t.SelectedRow >= len(t.Data) {
+		t.SelectedRow = len(t.Data) - 1
+	}
+	if t.SelectedRow < 0 {
+		t.SelectedRow = 0
+	}
+}
+
+func (t *selectableTable) ScrollUp() {
+	t.ScrollAmount(-1)
+}
+
+func (t *selectableTable) ScrollDown() {
+	t.ScrollAmount(1)
+}
+
+func (t *selectableTable) ScrollPageUp() {
+	t.ScrollAmount(-(t.Inner.Dy() - 1))
+}
+
+func (t *selectableTable) ScrollPageDown() {
+	t.ScrollAmount(t.Inner.Dy() - 1)
+}
+
+func (t *selectableTable) ScrollTop() {
+	t.SelectedRow = 0
+}
+
+func (t *selectableTable) ScrollBottom() {
+	t.ScrollAmount(len(t.Data))
+}
+
+// selectedCell returns a column of the row under the cursor
+func (t *selectableTable) selectedCell(col int) string {
+	if len(t.Data) == 0 || t.SelectedRow >= len(t.Data) {
+		return ""
+	}
+	return t.Data[t.SelectedRow][col]
+}
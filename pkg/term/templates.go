@@ -28,3 +28,46 @@ Containers:
          {{ end -}}
 {{ end -}}
 `
+
+const workloadDetailsTemplate = `Name:          {{ .Name }}
+Namespace:     {{ .Namespace }}
+Kind:          {{ .Kind }}
+Created:       {{ .Created }}
+
+Replicas:      {{ .Desired }} desired | {{ .Current }} current | {{ .Updated }} updated | {{ .Ready }} ready | {{ .Available }} available
+Strategy:      {{ if .Strategy }}{{ .Strategy }}{{ else }}n/a{{ end }}
+Selector:      {{ .Selector }}
+
+Images:
+{{- range $idx, $image := .Images }}
+   {{ $image }}
+{{- end }}
+`
+
+const nodeDetailsTemplate = `Name:          {{ .Name }}
+Roles:         {{ .Roles }}
+Status:        {{ .Status }}
+Kubelet:       {{ .KubeletVersion }}
+Created:       {{ .Created }}
+Unschedulable: {{ .Unschedulable }}
+
+Addresses:
+{{- range $idx, $addr := .Addresses }}
+   {{ $addr }}
+{{- end }}
+
+Taints:
+{{- range $idx, $taint := .Taints }}
+   {{ $taint }}
+{{- else }} <none>
+{{- end }}
+
+Conditions:
+{{- range $idx, $cond := .Conditions }}
+   {{ $cond }}
+{{- end }}
+
+Allocated resources ({{ .Pods }} pods):
+   CPU Requests:    {{ .CPURequests }} / {{ .CPUAllocatable }} ({{ .CPUPercent }}%)
+   Memory Requests: {{ .MemoryRequests }} / {{ .MemoryAllocatable }} ({{ .MemoryPercent }}%)
+`
@@ -2,15 +2,15 @@ package term
 
 import (
 	"bytes"
-	"context"
 	"fmt"
 	"html/template"
-	"io"
 	"runtime/debug"
 	"strings"
+	"time"
 
 	ui "github.com/gizak/termui/v3"
 	"github.com/gizak/termui/v3/widgets"
+	"k8s.io/apimachinery/pkg/util/duration"
 )
 
 type errorWithStack struct {
@@ -28,11 +28,37 @@ func (e *errorWithStack) Stack() string {
 	return e.errStack
 }
 
-func (c *controller) focusScroll(focus *widgets.List, direction string) {
+// scrollable is implemented by the panes that can take focus
+type scrollable interface {
+	ui.Drawable
+	ScrollUp()
+	ScrollDown()
+	ScrollPageUp()
+	ScrollPageDown()
+	ScrollTop()
+	ScrollBottom()
+}
+
+// blockOf returns the block of a pane for setting its title
+func blockOf(s scrollable) *ui.Block {
+	switch w := s.(type) {
+	case *widgets.List:
+		return &w.Block
+	case *selectableTable:
+		return &w.Block
+	case *styledList:
+		return &w.Block
+	case *terminalPane:
+		return &w.Block
+	}
+	return ui.NewBlock()
+}
+
+func (c *controller) focusScroll(focus scrollable, direction string) {
 	if empty(focus) {
 		return
 	}
-	c.setupIfLogWindow()
+	c.pauseLogSession()
 	switch direction {
 
 	case up:
@@ -64,19 +90,6 @@ func (c *controller) focusScroll(focus *widgets.List, direction string) {
 	}
 }
 
-func (c *controller) setupIfLogWindow() {
-	if focus == c.logWindow {
-		cancelIfNotNil(logCancel)
-		if !logsPaused {
-			c.logWindow.Title = fmt.Sprintf("%s   PAUSED: Press <enter> to resume ", c.logWindow.Title)
-			c.mux.Lock()
-			ui.Render(c.logWindow)
-			c.mux.Unlock()
-			logsPaused = true
-		}
-	}
-}
-
 func cancelIfNotNil(f func()) {
 	if f != nil {
 		f()
@@ -90,52 +103,115 @@ func newErrorWithStack(err error) (serr *errorWithStack) {
 	return serr
 }
 
-func (c *controller) getPodDetails(pod string) {
-	details, err := c.factory.GetPod(c.currentNamespace, pod)
+func (c *controller) getPodDetails(ns, pod string) {
+	details, err := c.factory.GetPod(ns, pod)
 	if err != nil {
 		c.errorChan <- newErrorWithStack(err)
 	} else {
 		var buf bytes.Buffer
 		t := template.Must(template.New("pod-details").Parse(podDetailsTemplate))
 		err = t.Execute(&buf, details)
+		if err != nil {
+			c.errorChan <- newErrorWithStack(err)
+			return
+		}
+		// like kubectl describe, finish with what has happened to the pod
+		events, err := c.factory.ListEvents(ns, "Pod", pod)
 		if err != nil {
 			c.errorChan <- newErrorWithStack(err)
 		} else {
-			c.detailsChan <- buf.String()
+			buf.WriteString("\n")
+			buf.WriteString(eventsSection(events))
 		}
+		c.detailsChan <- buf.String()
 	}
 }
 
 func (c *controller) selectPod() {
-	if empty(c.podList) {
+	ns, pod := c.getSelectedPod()
+	if pod == "" {
 		return
 	}
-	pod := c.getSelectedPod()
 	c.debug(fmt.Sprintf("Fetching details for %s", pod))
 	c.detailsChan <- fmt.Sprintf("Loading details for %s...\n", pod)
-	go c.getPodDetails(pod)
+	go c.getPodDetails(ns, pod)
+	if c.showPodManifest {
+		c.loadPodManifest(ns, pod)
+	}
+}
+
+// podDetailsPane is the pane showing the selected pod, either its details
+// or its full manifest
+func (c *controller) podDetailsPane() scrollable {
+	if c.showPodManifest {
+		return c.podManifestWindow
+	}
+	return c.detailsWindow
+}
+
+// togglePodManifest swaps the details pane between the details template
+// and the pod's full manifest, keeping focus on it if it had focus
+func (c *controller) togglePodManifest() {
+	if focus == c.podDetailsPane() {
+		block := blockOf(focus)
+		block.Title = strings.Replace(block.Title, " * ", "", 1)
+		c.showPodManifest = !c.showPodManifest
+		focus = c.podDetailsPane()
+		block = blockOf(focus)
+		block.Title = fmt.Sprintf(" * %s ", block.Title)
+	} else {
+		c.showPodManifest = !c.showPodManifest
+	}
+	if ns, pod := c.getSelectedPod(); c.showPodManifest && pod != "" {
+		c.loadPodManifest(ns, pod)
+	}
+}
+
+func (c *controller) loadPodManifest(ns, pod string) {
+	c.debug(fmt.Sprintf("Fetching manifest for %s", pod))
+	pane := c.podManifestWindow
+	pane.Rows = plainRows([]string{manifestLoading}, ui.NewStyle(ui.ColorWhite))
+	pane.ScrollTop()
+	go func() {
+		obj, err := c.factory.GetObject(podResource, ns, pod)
+		if err != nil {
+			c.errorChan <- newErrorWithStack(err)
+			return
+		}
+		c.podManifest = &manifest{object: obj}
+		c.drawManifest(pane, c.podManifest)
+		if pane == c.podManifestWindow && c.showPodManifest && c.navWindow.ActiveTabIndex == podsTab {
+			c.mux.Lock()
+			ui.Render(pane)
+			c.mux.Unlock()
+		}
+	}()
 }
 
 func (c *controller) switchPane() {
-	focus.Title = strings.Replace(focus.Title, " * ", "", 1)
+	block := blockOf(focus)
+	block.Title = strings.Replace(block.Title, " * ", "", 1)
 	if focus == c.podList {
-		focus = c.detailsWindow
-	} else if focus == c.detailsWindow {
-		focus = c.logWindow
+		focus = c.podDetailsPane()
+	} else if focus == c.podDetailsPane() {
+		focus = c.sessionPane()
 	} else {
 		focus = c.podList
 	}
-	focus.Title = fmt.Sprintf(" * %s ", focus.Title)
+	block = blockOf(focus)
+	block.Title = fmt.Sprintf(" * %s ", block.Title)
 	c.mux.Lock()
 	ui.Render(focus)
 	c.mux.Unlock()
 }
 
-func (c *controller) displayNamespaceList() {
-	cancelIfNotNil(logCancel)
-	c.navWindow.FocusLeft()
-	ui.Clear()
-	c.renderDefaults()
+// focusTab highlights one of tabPanes in the nav window
+func (c *controller) focusTab(idx int) {
+	c.navWindow.ActiveTabIndex = idx
+}
+
+func (c *controller) displayNamespaceList() (next int) {
+	c.focusTab(namespacesTab)
 	ch := make(chan string)
 	go func() {
 		newCh := make(chan string)
@@ -146,41 +222,57 @@ func (c *controller) displayNamespaceList() {
 			return
 		}
 	}()
-	c.pollNamespaces(ch)
-	return
+	return c.pollNamespaces(ch)
+}
+
+// getSelectedPod returns the namespace and name of the pod under the
+// cursor, which may be outside the current namespace when the pod list
+// spans every namespace
+func (c *controller) getSelectedPod() (ns, name string) {
+	if c.podList == nil || c.podList.SelectedRow >= len(c.pods) {
+		return
+	}
+	pod := c.pods[c.podList.SelectedRow]
+	return pod.Namespace, pod.Name
 }
 
-func (c *controller) getSelectedPod() string {
-	if empty(c.podList) {
+// podNamespace is the namespace the pod list watches. Pods are listed
+// across every namespace when filtering by node.
+func (c *controller) podNamespace() string {
+	if c.podFilter.FieldSelector != "" {
 		return ""
 	}
-	return c.podList.Rows[c.podList.SelectedRow]
+	return c.currentNamespace
 }
 
 func (c *controller) getSelectedNamespace() string {
 	return c.namespaceList.Rows[c.namespaceList.SelectedRow]
 }
 
-func empty(s *widgets.List) bool {
-	return len(s.Rows) == 0
+func empty(s scrollable) bool {
+	switch w := s.(type) {
+	case *widgets.List:
+		return len(w.Rows) == 0
+	case *selectableTable:
+		return len(w.Data) == 0
+	case *styledList:
+		return len(w.Rows) == 0
+	}
+	return true
 }
 
-func notEmpty(s *widgets.List) bool {
-	return len(s.Rows) != 0
+func notEmpty(s scrollable) bool {
+	return !empty(s)
 }
 
-type readerFunc func(p []byte) (n int, err error)
+// age formats the time since a timestamp the way kubectl does
+func age(t time.Time) string {
+	if t.IsZero() {
+		return "<unknown>"
+	}
+	return duration.ShortHumanDuration(time.Since(t))
+}
 
-func (rf readerFunc) Read(p []byte) (n int, err error) { return rf(p) }
+type writerFunc func(p []byte) (n int, err error)
 
-func asyncCopy(ctx context.Context, dst io.Writer, src io.Reader) (err error) {
-	_, err = io.Copy(dst, readerFunc(func(p []byte) (int, error) {
-		select {
-		case <-ctx.Done():
-			return 0, ctx.Err()
-		default:
-			return src.Read(p)
-		}
-	}))
-	return
-}
+func (wf writerFunc) Write(p []byte) (n int, err error) { return wf(p) }
@@ -0,0 +1,848 @@
+package term
+
+import (
+	"fmt"
+	"image"
+	"sync"
+	"unicode/utf8"
+
+	ui "github.com/gizak/termui/v3"
+	rw "github.com/mattn/go-runewidth"
+)
+
+// parser states of a vtScreen
+const (
+	vtGround = iota
+	vtEscape
+	vtCSI
+	vtOSC
+	vtString
+	vtStringEscape
+	vtCharset
+	vtHash
+)
+
+// vtTabWidth is where the fixed tab stops are
+const vtTabWidth = 8
+
+// vtMaxParamBytes is how much of the parameters of a control sequence is
+// kept. Like xterm, anything past it is ignored rather than buffered for a
+// program that never ends the sequence.
+const vtMaxParamBytes = 256
+
+// vtGraphics is the DEC special graphics character set, which programs
+// like top and dialog switch to for drawing lines
+var vtGraphics = map[rune]rune{
+	'`': '◆', 'a': '▒', 'f': '°', 'g': '±', 'j': '┘', 'k': '┐', 'l': '┌',
+	'm': '└', 'n': '┼', 'o': '⎺', 'p': '⎻', 'q': '─', 'r': '⎼', 's': '⎽',
+	't': '├', 'u': '┤', 'v': '┴', 'w': '┬', 'x': '│', 'y': '≤', 'z': '≥',
+	'{': 'π', '|': '≠', '}': '£', '~': '·',
+}
+
+// vtCell is one character cell of a screen. The second half of a wide
+// character is a cell with no rune.
+type vtCell struct {
+	r     rune
+	style ui.Style
+}
+
+type vtCursor struct {
+	x, y  int
+	style ui.Style
+}
+
+// vtScreen is a VT100/xterm screen model. Output from a program running in
+// a terminal is written to it, and it interprets the escape sequences in
+// it into a grid of styled cells and a cursor, the way a terminal
+// emulator does, so that full screen programs can be drawn in a pane.
+type vtScreen struct {
+	mux sync.Mutex
+
+	width, height int
+	lines         [][]vtCell
+	// the screen that isn't showing, the main one while full screen
+	// programs are on the alternate one
+	other [][]vtCell
+	alt   bool
+
+	x, y     int
+	wrapNext bool
+	style    ui.Style
+	saved    vtCursor
+	// the scrolling region, both lines included
+	top, bottom int
+
+	autowrap     bool
+	insert       bool
+	cursorHidden bool
+	appCursor    bool
+	graphics     [2]bool
+	shifted      bool
+	last         rune
+
+	state       int
+	params      []byte
+	private     byte
+	charsetSlot int
+	pending     []byte
+	replies     []byte
+	reply       func([]byte)
+	resized     func(width, height int)
+}
+
+// newVTScreen returns an empty screen. reply, when not nil, is given the
+// answers to queries the program makes about the terminal, which belong
+// on its stdin.
+func newVTScreen(width, height int, reply func([]byte)) *vtScreen {
+	s := &vtScreen{reply: reply}
+	s.Resize(width, height)
+	s.reset()
+	return s
+}
+
+func (s *vtScreen) reset() {
+	s.style = ui.StyleClear
+	s.lines = s.blankLines(s.height)
+	s.other = s.blankLines(s.height)
+	s.alt = false
+	s.x, s.y, s.wrapNext = 0, 0, false
+	s.saved = vtCursor{style: ui.StyleClear}
+	s.top, s.bottom = 0, s.height-1
+	s.autowrap, s.insert, s.cursorHidden = true, false, false
+	s.appCursor = false
+	s.graphics = [2]bool{}
+	s.shifted = false
+	s.state = vtGround
+}
+
+// Resize changes the size of the screen, keeping what is on it where it
+// can and the cursor in view
+func (s *vtScreen) Resize(width, height int) {
+	if width < 1 {
+		width = 1
+	}
+	if height < 1 {
+		height = 1
+	}
+	s.mux.Lock()
+	if width == s.width && height == s.height {
+		s.mux.Unlock()
+		return
+	}
+	// drop lines off the top when the cursor would end up below the
+	// bottom
+	shift := 0
+	if s.y >= height {
+		shift = s.y - height + 1
+	}
+	resize := func(lines [][]vtCell, shift int) [][]vtCell {
+		resized := make([][]vtCell, height)
+		for y := range resized {
+			resized[y] = make([]vtCell, width)
+			for x := range resized[y] {
+				resized[y][x] = vtCell{r: ' ', style: ui.StyleClear}
+			}
+			if y+shift < len(lines) {
+				copy(resized[y], lines[y+shift])
+			}
+		}
+		return resized
+	}
+	s.lines = resize(s.lines, shift)
+	s.other = resize(s.other, 0)
+	s.width, s.height = width, height
+	s.y -= shift
+	s.top, s.bottom = 0, height-1
+	s.wrapNext = false
+	s.clampCursor()
+	resized := s.resized
+	s.mux.Unlock()
+
+	if resized != nil {
+		resized(width, height)
+	}
+}
+
+// OnResize sets a function called with the new size whenever the screen
+// is resized, so the program can be told about it
+func (s *vtScreen) OnResize(f func(width, height int)) {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+	s.resized = f
+}
+
+// Size returns the width and height of the screen
+func (s *vtScreen) Size() (width, height int) {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+	return s.width, s.height
+}
+
+// AppCursorKeys reports whether the program asked for the arrow keys to
+// be sent in application mode
+func (s *vtScreen) AppCursorKeys() bool {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+	return s.appCursor
+}
+
+// Write interprets output from the program
+func (s *vtScreen) Write(p []byte) (int, error) {
+	s.mux.Lock()
+	s.pending = append(s.pending, p...)
+	for len(s.pending) > 0 {
+		r, size := rune(s.pending[0]), 1
+		if r >= utf8.RuneSelf {
+			if !utf8.FullRune(s.pending) {
+				// wait for the rest of the character
+				break
+			}
+			r, size = utf8.DecodeRune(s.pending)
+		}
+		s.pending = s.pending[size:]
+		s.feed(r)
+	}
+	replies := s.replies
+	s.replies = nil
+	s.mux.Unlock()
+
+	// the program may be blocked writing more output, so don't hold the
+	// screen while answering it
+	if len(replies) > 0 && s.reply != nil {
+		s.reply(replies)
+	}
+	return len(p), nil
+}
+
+func (s *vtScreen) feed(r rune) {
+	switch s.state {
+	case vtGround:
+		if r < 0x20 || r == 0x7f {
+			s.control(r)
+		} else {
+			s.print(r)
+		}
+
+	case vtEscape:
+		s.escape(r)
+
+	case vtCSI:
+		switch {
+		case r == 0x1b:
+			s.state = vtEscape
+		case r < 0x20:
+			s.control(r)
+		case r >= '0' && r <= ';':
+			if len(s.params) < vtMaxParamBytes {
+				s.params = append(s.params, byte(r))
+			}
+		case r >= '<' && r <= '?':
+			if len(s.params) == 0 {
+				s.private = byte(r)
+			}
+		case r >= 0x20 && r <= 0x2f:
+			// intermediates, none of the sequences handled use them
+		case r >= 0x40 && r <= 0x7e:
+			s.state = vtGround
+			s.csi(r)
+		default:
+			s.state = vtGround
+		}
+
+	case vtOSC, vtString:
+		// titles, hyperlinks and device control strings are ignored
+		switch r {
+		case 0x07:
+			if s.state == vtOSC {
+				s.state = vtGround
+			}
+		case 0x1b:
+			s.state = vtStringEscape
+		}
+
+	case vtStringEscape:
+		s.state = vtGround
+		if r != '\\' {
+			s.escape(r)
+		}
+
+	case vtCharset:
+		if s.charsetSlot >= 0 {
+			s.graphics[s.charsetSlot] = r == '0'
+		}
+		s.state = vtGround
+
+	case vtHash:
+		s.state = vtGround
+	}
+}
+
+func (s *vtScreen) control(r rune) {
+	switch r {
+	case '\b':
+		if s.x > 0 {
+			s.x--
+		}
+		s.wrapNext = false
+	case '\t':
+		s.x = (s.x/vtTabWidth + 1) * vtTabWidth
+		if s.x >= s.width {
+			s.x = s.width - 1
+		}
+		s.wrapNext = false
+	case '\n', '\v', '\f':
+		s.lineFeed()
+	case '\r':
+		s.x, s.wrapNext = 0, false
+	case 0x0e:
+		s.shifted = true
+	case 0x0f:
+		s.shifted = false
+	case 0x1b:
+		s.state = vtEscape
+	}
+}
+
+func (s *vtScreen) escape(r rune) {
+	s.state = vtGround
+	switch r {
+	case '[':
+		s.state = vtCSI
+		s.params = s.params[:0]
+		s.private = 0
+	case ']':
+		s.state = vtOSC
+	case 'P', 'X', '^', '_':
+		s.state = vtString
+	case '(', ')':
+		s.state = vtCharset
+		s.charsetSlot = int(r - '(')
+	case '*', '+':
+		// only G0 and G1 can be shifted into, G2 and G3 are ignored
+		s.state = vtCharset
+		s.charsetSlot = -1
+	case '#':
+		s.state = vtHash
+	case '7':
+		s.saveCursor()
+	case '8':
+		s.restoreCursor()
+	case 'D':
+		s.lineFeed()
+	case 'E':
+		s.x = 0
+		s.lineFeed()
+	case 'M':
+		s.reverseIndex()
+	case 'c':
+		s.reset()
+	}
+}
+
+func (s *vtScreen) print(r rune) {
+	set := 0
+	if s.shifted {
+		set = 1
+	}
+	if s.graphics[set] {
+		if g, ok := vtGraphics[r]; ok {
+			r = g
+		}
+	}
+	width := rw.RuneWidth(r)
+	if width == 0 {
+		// combining characters are dropped rather than drawn over the
+		// cell before them
+		return
+	}
+	if s.wrapNext && s.autowrap {
+		s.x = 0
+		s.lineFeed()
+	}
+	s.wrapNext = false
+	if width > s.width {
+		return
+	}
+	if s.x+width > s.width {
+		// wide characters that don't fit go to the next line
+		if !s.autowrap {
+			return
+		}
+		s.eraseCells(s.y, s.x, s.width)
+		s.x = 0
+		s.lineFeed()
+	}
+	line := s.lines[s.y]
+	if s.insert {
+		copy(line[s.x+width:], line[s.x:])
+	}
+	line[s.x] = vtCell{r: r, style: s.style}
+	if width == 2 {
+		line[s.x+1] = vtCell{style: s.style}
+	}
+	s.last = r
+	s.x += width
+	if s.x >= s.width {
+		s.x = s.width - 1
+		s.wrapNext = true
+	}
+}
+
+// vtParam returns a parameter of a control sequence, or def when it is
+// missing or zero
+func vtParam(params []int, idx, def int) int {
+	if idx >= len(params) || params[idx] == 0 {
+		return def
+	}
+	return params[idx]
+}
+
+func (s *vtScreen) parseParams() []int {
+	params := make([]int, 0)
+	current, seen := 0, false
+	for _, b := range s.params {
+		switch {
+		case b >= '0' && b <= '9':
+			current = current*10 + int(b-'0')
+			if current > 65535 {
+				current = 65535
+			}
+			seen = true
+		default:
+			// sub-parameters separated by colons are read as parameters
+			params = append(params, current)
+			current, seen = 0, false
+		}
+	}
+	if seen || len(s.params) > 0 {
+		params = append(params, current)
+	}
+	return params
+}
+
+func (s *vtScreen) csi(final rune) {
+	params := s.parseParams()
+	n := vtParam(params, 0, 1)
+	if s.private != 0 && s.private != '?' && final != 'c' {
+		// xterm extensions like modifyOtherKeys
+		return
+	}
+	s.wrapNext = false
+
+	switch final {
+	case '@':
+		line := s.lines[s.y]
+		if n > s.width-s.x {
+			n = s.width - s.x
+		}
+		copy(line[s.x+n:], line[s.x:])
+		s.eraseCells(s.y, s.x, s.x+n)
+	case 'A':
+		s.y -= n
+		if s.y < s.top && s.y+n >= s.top {
+			s.y = s.top
+		}
+	case 'B', 'e':
+		s.y += n
+		if s.y > s.bottom && s.y-n <= s.bottom {
+			s.y = s.bottom
+		}
+	case 'C', 'a':
+		s.x += n
+	case 'D':
+		s.x -= n
+	case 'E':
+		s.y += n
+		s.x = 0
+	case 'F':
+		s.y -= n
+		s.x = 0
+	case 'G', '`':
+		s.x = n - 1
+	case 'H', 'f':
+		s.y = vtParam(params, 0, 1) - 1
+		s.x = vtParam(params, 1, 1) - 1
+	case 'd':
+		s.y = n - 1
+	case 'J':
+		switch vtParam(params, 0, 0) {
+		case 0:
+			s.eraseCells(s.y, s.x, s.width)
+			s.eraseLines(s.y+1, s.height)
+		case 1:
+			s.eraseLines(0, s.y)
+			s.eraseCells(s.y, 0, s.x+1)
+		case 2, 3:
+			s.eraseLines(0, s.height)
+		}
+	case 'K':
+		switch vtParam(params, 0, 0) {
+		case 0:
+			s.eraseCells(s.y, s.x, s.width)
+		case 1:
+			s.eraseCells(s.y, 0, s.x+1)
+		case 2:
+			s.eraseCells(s.y, 0, s.width)
+		}
+	case 'L':
+		if s.y >= s.top && s.y <= s.bottom {
+			s.scrollDown(s.y, n)
+			s.x = 0
+		}
+	case 'M':
+		if s.y >= s.top && s.y <= s.bottom {
+			s.scrollUp(s.y, n)
+			s.x = 0
+		}
+	case 'P':
+		line := s.lines[s.y]
+		if n > s.width-s.x {
+			n = s.width - s.x
+		}
+		copy(line[s.x:], line[s.x+n:])
+		s.eraseCells(s.y, s.width-n, s.width)
+	case 'X':
+		s.eraseCells(s.y, s.x, s.x+n)
+	case 'S':
+		s.scrollUp(s.top, n)
+	case 'T':
+		// with more parameters this is xterm mouse tracking
+		if len(params) <= 1 {
+			s.scrollDown(s.top, n)
+		}
+	case 'b':
+		if s.last != 0 {
+			for i := 0; i < n && i < s.width*s.height; i++ {
+				s.print(s.last)
+			}
+		}
+	case 'm':
+		if s.private == 0 {
+			s.sgr(params)
+		}
+	case 'r':
+		top, bottom := vtParam(params, 0, 1)-1, vtParam(params, 1, s.height)-1
+		if bottom >= s.height {
+			bottom = s.height - 1
+		}
+		if top < bottom {
+			s.top, s.bottom = top, bottom
+			s.x, s.y = 0, 0
+		}
+	case 's':
+		if s.private == 0 {
+			s.saveCursor()
+		}
+	case 'u':
+		s.restoreCursor()
+	case 'h', 'l':
+		for _, mode := range params {
+			s.setMode(mode, final == 'h')
+		}
+	case 'n':
+		switch vtParam(params, 0, 0) {
+		case 5:
+			s.replies = append(s.replies, "\x1b[0n"...)
+		case 6:
+			s.replies = append(s.replies, fmt.Sprintf("\x1b[%d;%dR", s.y+1, s.x+1)...)
+		}
+	case 'c':
+		switch s.private {
+		case 0:
+			// a VT100 with advanced video
+			s.replies = append(s.replies, "\x1b[?1;2c"...)
+		case '>':
+			s.replies = append(s.replies, "\x1b[>0;0;0c"...)
+		}
+	}
+	s.clampCursor()
+}
+
+func (s *vtScreen) setMode(mode int, on bool) {
+	if s.private != '?' {
+		if mode == 4 {
+			s.insert = on
+		}
+		return
+	}
+	switch mode {
+	case 1:
+		s.appCursor = on
+	case 7:
+		s.autowrap = on
+	case 25:
+		s.cursorHidden = !on
+	case 47, 1047:
+		s.useAltScreen(on)
+	case 1049:
+		if on {
+			s.saveCursor()
+			s.useAltScreen(true)
+			s.eraseLines(0, s.height)
+		} else {
+			s.useAltScreen(false)
+			s.restoreCursor()
+		}
+	}
+}
+
+func (s *vtScreen) useAltScreen(on bool) {
+	if on == s.alt {
+		return
+	}
+	s.lines, s.other = s.other, s.lines
+	s.alt = on
+	if on {
+		s.eraseLines(0, s.height)
+	}
+}
+
+func (s *vtScreen) sgr(params []int) {
+	if len(params) == 0 {
+		params = []int{0}
+	}
+	for i := 0; i < len(params); i++ {
+		switch p := params[i]; {
+		case p == 0:
+			s.style = ui.StyleClear
+		case p == 1:
+			s.style.Modifier |= ui.ModifierBold
+		case p == 4:
+			s.style.Modifier |= ui.ModifierUnderline
+		case p == 7:
+			s.style.Modifier |= ui.ModifierReverse
+		case p == 22:
+			s.style.Modifier &^= ui.ModifierBold
+		case p == 24:
+			s.style.Modifier &^= ui.ModifierUnderline
+		case p == 27:
+			s.style.Modifier &^= ui.ModifierReverse
+		case p >= 30 && p <= 37:
+			s.style.Fg = ui.Color(p - 30)
+		case p == 39:
+			s.style.Fg = ui.ColorClear
+		case p >= 40 && p <= 47:
+			s.style.Bg = ui.Color(p - 40)
+		case p == 49:
+			s.style.Bg = ui.ColorClear
+		case p >= 90 && p <= 97:
+			s.style.Fg = ui.Color(p - 90 + 8)
+		case p >= 100 && p <= 107:
+			s.style.Bg = ui.Color(p - 100 + 8)
+		case p == 38 || p == 48:
+			color, used := extendedColor(params[i+1:])
+			i += used
+			if color == nil {
+				continue
+			}
+			if p == 38 {
+				s.style.Fg = *color
+			} else {
+				s.style.Bg = *color
+			}
+		}
+	}
+}
+
+// extendedColor reads a 256 colour or RGB colour following a 38 or 48,
+// returning how many parameters it took. RGB colours are drawn with the
+// nearest colour of the 256 colour cube.
+func extendedColor(params []int) (*ui.Color, int) {
+	if len(params) == 0 {
+		return nil, 0
+	}
+	switch params[0] {
+	case 5:
+		if len(params) < 2 {
+			return nil, len(params)
+		}
+		color := ui.Color(params[1] & 0xff)
+		return &color, 2
+	case 2:
+		if len(params) < 4 {
+			return nil, len(params)
+		}
+		cube := func(v int) int {
+			if v > 255 {
+				v = 255
+			}
+			return (v*5 + 127) / 255
+		}
+		color := ui.Color(16 + 36*cube(params[1]) + 6*cube(params[2]) + cube(params[3]))
+		return &color, 4
+	}
+	return nil, 1
+}
+
+func (s *vtScreen) lineFeed() {
+	s.wrapNext = false
+	if s.y == s.bottom {
+		s.scrollUp(s.top, 1)
+	} else if s.y < s.height-1 {
+		s.y++
+	}
+}
+
+func (s *vtScreen) reverseIndex() {
+	s.wrapNext = false
+	if s.y == s.top {
+		s.scrollDown(s.top, 1)
+	} else if s.y > 0 {
+		s.y--
+	}
+}
+
+// scrollUp moves the lines from top to the bottom of the scrolling region
+// up, blanking the lines left at the bottom
+func (s *vtScreen) scrollUp(top, n int) {
+	if n > s.bottom-top+1 {
+		n = s.bottom - top + 1
+	}
+	copy(s.lines[top:s.bottom+1], s.lines[top+n:s.bottom+1])
+	for y := s.bottom - n + 1; y <= s.bottom; y++ {
+		s.lines[y] = s.blankLine()
+	}
+}
+
+// scrollDown moves the lines from top to the bottom of the scrolling
+// region down, blanking the lines left at top
+func (s *vtScreen) scrollDown(top, n int) {
+	if n > s.bottom-top+1 {
+		n = s.bottom - top + 1
+	}
+	copy(s.lines[top+n:s.bottom+1], s.lines[top:s.bottom+1-n])
+	for y := top; y < top+n; y++ {
+		s.lines[y] = s.blankLine()
+	}
+}
+
+// blankLine returns an erased line, which takes the current background
+// colour like xterm does
+func (s *vtScreen) blankLine() []vtCell {
+	line := make([]vtCell, s.width)
+	for x := range line {
+		line[x] = vtCell{r: ' ', style: ui.Style{Fg: ui.ColorClear, Bg: s.style.Bg}}
+	}
+	return line
+}
+
+func (s *vtScreen) blankLines(n int) [][]vtCell {
+	lines := make([][]vtCell, n)
+	for y := range lines {
+		lines[y] = s.blankLine()
+	}
+	return lines
+}
+
+func (s *vtScreen) eraseLines(from, to int) {
+	for y := from; y < to && y < s.height; y++ {
+		s.lines[y] = s.blankLine()
+	}
+}
+
+func (s *vtScreen) eraseCells(y, from, to int) {
+	if to > s.width {
+		to = s.width
+	}
+	for x := from; x < to; x++ {
+		s.lines[y][x] = vtCell{r: ' ', style: ui.Style{Fg: ui.ColorClear, Bg: s.style.Bg}}
+	}
+}
+
+func (s *vtScreen) saveCursor() {
+	s.saved = vtCursor{x: s.x, y: s.y, style: s.style}
+}
+
+func (s *vtScreen) restoreCursor() {
+	s.x, s.y, s.style = s.saved.x, s.saved.y, s.saved.style
+	s.wrapNext = false
+	s.clampCursor()
+}
+
+func (s *vtScreen) clampCursor() {
+	if s.x < 0 {
+		s.x = 0
+	} else if s.x >= s.width {
+		s.x = s.width - 1
+	}
+	if s.y < 0 {
+		s.y = 0
+	} else if s.y >= s.height {
+		s.y = s.height - 1
+	}
+}
+
+// Text returns what is on the screen, one string per line with trailing
+// blanks trimmed
+func (s *vtScreen) Text() []string {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+	text := make([]string, 0)
+	for _, line := range s.lines {
+		runes := make([]rune, 0)
+		for _, cell := range line {
+			if cell.r != 0 {
+				runes = append(runes, cell.r)
+			}
+		}
+		end := len(runes)
+		for end > 0 && runes[end-1] == ' ' {
+			end--
+		}
+		text = append(text, string(runes[:end]))
+	}
+	return text
+}
+
+// draw copies the screen into a termui buffer, showing the cursor as a
+// reversed cell
+func (s *vtScreen) draw(buf *ui.Buffer, area image.Rectangle) {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+	for y := 0; y < s.height && y < area.Dy(); y++ {
+		for x := 0; x < s.width && x < area.Dx(); x++ {
+			cell := s.lines[y][x]
+			if cell.r == 0 {
+				continue
+			}
+			style := cell.style
+			if !s.cursorHidden && x == s.x && y == s.y {
+				style.Modifier ^= ui.ModifierReverse
+			}
+			buf.SetCell(ui.NewCell(cell.r, style), image.Pt(area.Min.X+x, area.Min.Y+y))
+		}
+	}
+}
+
+// terminalPane is a termui widget showing a vtScreen
+type terminalPane struct {
+	ui.Block
+
+	Screen *vtScreen
+}
+
+func newTerminalPane(screen *vtScreen) *terminalPane {
+	return &terminalPane{
+		Block:  *ui.NewBlock(),
+		Screen: screen,
+	}
+}
+
+// Fit resizes the screen to the inside of the pane
+func (t *terminalPane) Fit() {
+	t.Screen.Resize(t.Inner.Dx(), t.Inner.Dy())
+}
+
+func (t *terminalPane) Draw(buf *ui.Buffer) {
+	t.Block.Draw(buf)
+	t.Screen.draw(buf, t.Inner)
+}
+
+// the pane takes focus in the pods view, but there is no history to
+// scroll through
+func (t *terminalPane) ScrollUp()       {}
+func (t *terminalPane) ScrollDown()     {}
+func (t *terminalPane) ScrollPageUp()   {}
+func (t *terminalPane) ScrollPageDown() {}
+func (t *terminalPane) ScrollTop()      {}
+func (t *terminalPane) ScrollBottom()   {}
@@ -0,0 +1,128 @@
+package term
+
+import (
+	"strings"
+	"testing"
+
+	ui "github.com/gizak/termui/v3"
+)
+
+// vtFilled is written before the erase tests, so there is something on
+// every line to erase
+const vtFilled = "abcde\r\nfghij\r\nklmno"
+
+func TestVTScreenText(t *testing.T) {
+	for _, tc := range []struct {
+		name          string
+		width, height int
+		input         string
+		want          []string
+	}{
+		// cursor position
+		{"cup", 5, 3, "\x1b[2;3Hx", []string{"", "  x", ""}},
+		{"cup home", 5, 3, "abc\x1b[Hx", []string{"xbc", "", ""}},
+		{"cup clamped", 5, 3, "\x1b[9;9Hx", []string{"", "", "    x"}},
+		{"cursor moves", 5, 3, "\x1b[3Bx\x1b[2Ay\x1b[2Dz", []string{"zy", "", "x"}},
+
+		// erase in display
+		{"ed below", 5, 3, vtFilled + "\x1b[2;3H\x1b[J", []string{"abcde", "fg", ""}},
+		{"ed above", 5, 3, vtFilled + "\x1b[2;3H\x1b[1J", []string{"", "   ij", "klmno"}},
+		{"ed all", 5, 3, vtFilled + "\x1b[2;3H\x1b[2J", []string{"", "", ""}},
+
+		// erase in line
+		{"el right", 5, 3, vtFilled + "\x1b[2;3H\x1b[K", []string{"abcde", "fg", "klmno"}},
+		{"el left", 5, 3, vtFilled + "\x1b[2;3H\x1b[1K", []string{"abcde", "   ij", "klmno"}},
+		{"el all", 5, 3, vtFilled + "\x1b[2;3H\x1b[2K", []string{"abcde", "", "klmno"}},
+
+		// scrolling regions
+		{"region scrolls up", 5, 4, "a\r\nb\r\nc\r\nd\x1b[2;3r\x1b[3;1H\nx", []string{"a", "c", "x", "d"}},
+		{"region scrolls down", 5, 4, "a\r\nb\r\nc\r\nd\x1b[2;3r\x1b[2;1H\x1bMx", []string{"a", "x", "b", "d"}},
+		{"region su", 5, 4, "a\r\nb\r\nc\r\nd\x1b[2;3r\x1b[S", []string{"a", "c", "", "d"}},
+		{"region il", 5, 4, "a\r\nb\r\nc\r\nd\x1b[2;3r\x1b[2;1H\x1b[Lx", []string{"a", "x", "b", "d"}},
+		{"region outside", 5, 4, "a\r\nb\r\nc\r\nd\x1b[2;3r\x1b[4;1H\nx", []string{"a", "b", "c", "x"}},
+		{"region reset", 5, 3, "a\r\nb\r\nc\x1b[1;2r\x1b[r\x1b[3;1H\nx", []string{"b", "c", "x"}},
+
+		// wrapping
+		{"wraps", 5, 2, "abcdefg", []string{"abcde", "fg"}},
+		{"wrap scrolls", 5, 2, "abcdefghijkl", []string{"fghij", "kl"}},
+		{"wrap is delayed", 5, 2, "abcde\r\nf", []string{"abcde", "f"}},
+		{"wrap is delayed at bottom", 5, 2, "\x1b[2;1Habcde", []string{"", "abcde"}},
+		{"no autowrap", 5, 2, "\x1b[?7labcdefg", []string{"abcdg", ""}},
+
+		// wide characters
+		{"wide", 5, 2, "a世b", []string{"a世b", ""}},
+		{"wide wraps whole", 5, 2, "abcd世", []string{"abcd", "世"}},
+		{"wide fills line", 5, 2, "a世世b", []string{"a世世", "b"}},
+		{"wide without autowrap", 5, 2, "\x1b[?7labcd世", []string{"abcd", ""}},
+		{"combining dropped", 5, 2, "éx", []string{"ex", ""}},
+
+		// the alternate screen
+		{"alt screen", 5, 2, "main\x1b[?1049h\x1b[Halt", []string{"alt", ""}},
+		{"alt screen is blank", 5, 2, "ab\r\ncd\x1b[?1049h", []string{"", ""}},
+		{"alt screen left", 5, 2, "main\x1b[?1049h\x1b[Halt\x1b[?1049l", []string{"main", ""}},
+		{"alt screen restores cursor", 5, 2, "ab\x1b[?1049h\x1b[2;4Hx\x1b[?1049lc", []string{"abc", ""}},
+		{"alt screen 47", 5, 2, "main\x1b[?47h\x1b[Halt\x1b[?47l", []string{"main", ""}},
+	} {
+		s := newVTScreen(tc.width, tc.height, nil)
+		s.Write([]byte(tc.input))
+		if got := s.Text(); strings.Join(got, "|") != strings.Join(tc.want, "|") {
+			t.Errorf("%s: got %q, want %q", tc.name, got, tc.want)
+		}
+	}
+}
+
+func TestVTScreenSGR(t *testing.T) {
+	blue := ui.Color(4)
+	for _, tc := range []struct {
+		name  string
+		input string
+		want  ui.Style
+	}{
+		{"none", "x", ui.StyleClear},
+		{"fg", "\x1b[31mx", ui.Style{Fg: ui.ColorRed, Bg: ui.ColorClear}},
+		{"bg", "\x1b[44mx", ui.Style{Fg: ui.ColorClear, Bg: blue}},
+		{"bright", "\x1b[91;102mx", ui.Style{Fg: ui.Color(9), Bg: ui.Color(10)}},
+		{"bold", "\x1b[1mx", ui.Style{Fg: ui.ColorClear, Bg: ui.ColorClear, Modifier: ui.ModifierBold}},
+		{"combined", "\x1b[1;4;33mx", ui.Style{Fg: ui.ColorYellow, Bg: ui.ColorClear, Modifier: ui.ModifierBold | ui.ModifierUnderline}},
+		{"unbold", "\x1b[1;4m\x1b[22mx", ui.Style{Fg: ui.ColorClear, Bg: ui.ColorClear, Modifier: ui.ModifierUnderline}},
+		{"reset", "\x1b[1;31;44m\x1b[0mx", ui.StyleClear},
+		{"reset empty", "\x1b[1;31;44m\x1b[mx", ui.StyleClear},
+		{"default colours", "\x1b[31;44m\x1b[39;49mx", ui.StyleClear},
+		{"256 colours", "\x1b[38;5;200;48;5;17mx", ui.Style{Fg: ui.Color(200), Bg: ui.Color(17)}},
+		{"rgb", "\x1b[38;2;255;0;0mx", ui.Style{Fg: ui.Color(196), Bg: ui.ColorClear}},
+		{"truncated rgb", "\x1b[38;2;255mx", ui.StyleClear},
+		{"private ignored", "\x1b[>4;2mx", ui.StyleClear},
+	} {
+		s := newVTScreen(5, 2, nil)
+		s.Write([]byte(tc.input))
+		if got := s.lines[0][0].style; got != tc.want {
+			t.Errorf("%s: got %+v, want %+v", tc.name, got, tc.want)
+		}
+	}
+}
+
+func TestVTScreenWideCharacterCells(t *testing.T) {
+	s := newVTScreen(5, 1, nil)
+	s.Write([]byte("\x1b[32m世"))
+	if s.lines[0][0].r != '世' || s.lines[0][1].r != 0 {
+		t.Errorf("expected a wide character and an empty cell, got %q and %q", s.lines[0][0].r, s.lines[0][1].r)
+	}
+	if s.lines[0][1].style != s.lines[0][0].style {
+		t.Errorf("expected both halves of a wide character to be styled alike")
+	}
+	if s.x != 2 {
+		t.Errorf("expected the cursor two cells on, got %d", s.x)
+	}
+}
+
+func TestVTScreenParamsAreCapped(t *testing.T) {
+	s := newVTScreen(5, 2, nil)
+	s.Write([]byte("\x1b[" + strings.Repeat("1;", 100000)))
+	if len(s.params) > vtMaxParamBytes {
+		t.Errorf("expected at most %d parameter bytes, got %d", vtMaxParamBytes, len(s.params))
+	}
+	s.Write([]byte("Hx"))
+	if got := s.Text(); got[0] != "x" {
+		t.Errorf("expected the sequence to end normally, got %q", got)
+	}
+}
@@ -0,0 +1,266 @@
+package term
+
+import (
+	"bytes"
+	"fmt"
+	"strconv"
+	"text/template"
+	"unicode"
+
+	ui "github.com/gizak/termui/v3"
+	"github.com/gizak/termui/v3/widgets"
+	"github.com/tinyzimmer/kubeconsole/pkg/k8sutils"
+)
+
+const (
+	workloadsTitle    = " Workloads "
+	workloadsLoading  = "Loading workloads..."
+	workloadsNoNS     = "Select a namespace first"
+	workloadDetsTitle = " Workload Details "
+)
+
+var workloadColumns = []string{"KIND", "NAME", "DESIRED", "CURRENT", "READY", "UP-TO-DATE", "AVAILABLE", "AGE"}
+
+func (c *controller) newWorkloadList() (t *selectableTable) {
+	t = newSelectableTable(workloadColumns...)
+	t.Title = fmt.Sprintf(" %s   Namespace: %s ", workloadsTitle, c.currentNamespace)
+	t.TextStyle = ui.NewStyle(ui.ColorCyan)
+	x, y := ui.TerminalDimensions()
+	t.SetRect(0, 3, x, y/2)
+
+	c.workloads = nil
+	if c.currentNamespace == "" {
+		t.Data = [][]string{{workloadsNoNS}}
+		return
+	}
+	t.Data = [][]string{{workloadsLoading}}
+
+	go func() {
+		workloads, err := c.factory.ListWorkloads(c.currentNamespace)
+		if err != nil {
+			c.errorChan <- newErrorWithStack(err)
+			return
+		}
+		c.debug(fmt.Sprintf("Retrieved %d workloads in %s", len(workloads), c.currentNamespace))
+		c.workloads = workloads
+		t.Data, t.DataStyles = workloadRows(workloads)
+		if c.navWindow.ActiveTabIndex == workloadsTab {
+			c.mux.Lock()
+			ui.Render(t)
+			c.mux.Unlock()
+		}
+	}()
+	return
+}
+
+func newWorkloadDetailsWindow() (*widgets.List, chan string) {
+	pane, ch := newDetailsWindow()
+	pane.Title = workloadDetsTitle
+	x, y := ui.TerminalDimensions()
+	pane.SetRect(0, y/2, x, y-3)
+	return pane, ch
+}
+
+// workloadRows formats workload summaries into table rows, colouring the
+// workloads that don't have all their replicas ready
+func workloadRows(workloads []k8sutils.WorkloadSummary) (rows [][]string, styles map[int]ui.Style) {
+	rows = make([][]string, 0)
+	styles = make(map[int]ui.Style)
+	for idx, w := range workloads {
+		rows = append(rows, []string{
+			w.Kind,
+			w.Name,
+			fmt.Sprintf("%d", w.Desired),
+			fmt.Sprintf("%d", w.Current),
+			fmt.Sprintf("%d", w.Ready),
+			fmt.Sprintf("%d", w.Updated),
+			fmt.Sprintf("%d", w.Available),
+			age(w.Created),
+		})
+		if w.Ready < w.Desired {
+			styles[idx] = ui.NewStyle(ui.ColorYellow)
+		}
+	}
+	return
+}
+
+// renderWorkloads renders the panes of the workloads view
+func (c *controller) renderWorkloads() {
+	c.mux.Lock()
+	defer c.mux.Unlock()
+	ui.Render(
+		c.navWindow,
+		c.serverWindow,
+		c.helpWindow,
+		c.workloadList,
+		c.workloadDetails,
+	)
+}
+
+func (c *controller) getSelectedWorkload() *k8sutils.WorkloadSummary {
+	if c.workloadList == nil || c.workloadList.SelectedRow >= len(c.workloads) {
+		return nil
+	}
+	return &c.workloads[c.workloadList.SelectedRow]
+}
+
+func (c *controller) selectWorkload() {
+	workload := c.getSelectedWorkload()
+	if workload == nil {
+		return
+	}
+	c.debug(fmt.Sprintf("Fetching details for %s/%s", workload.Kind, workload.Name))
+	c.workloadDetailsChan <- fmt.Sprintf("Loading details for %s...\n", workload.Name)
+	go c.getWorkloadDetails(workload.Kind, workload.Name)
+}
+
+func (c *controller) getWorkloadDetails(kind, name string) {
+	details, err := c.factory.GetWorkload(c.currentNamespace, kind, name)
+	if err != nil {
+		c.errorChan <- newErrorWithStack(err)
+		return
+	}
+	var buf bytes.Buffer
+	t := template.Must(template.New("workload-details").Parse(workloadDetailsTemplate))
+	if err = t.Execute(&buf, details); err != nil {
+		c.errorChan <- newErrorWithStack(err)
+		return
+	}
+	c.workloadDetailsChan <- buf.String()
+}
+
+// filterPodsByWorkload points the pod list at the pods matching the
+// selected workload's label selector
+func (c *controller) filterPodsByWorkload() bool {
+	workload := c.getSelectedWorkload()
+	if workload == nil {
+		return false
+	}
+	// an empty selector matches every pod in the namespace
+	if workload.Selector == "" {
+		c.errorChan <- newErrorWithStack(fmt.Errorf("%s/%s has no pod selector", workload.Kind, workload.Name))
+		return false
+	}
+	c.debug(fmt.Sprintf("Filtering pods by %s/%s selector: %s", workload.Kind, workload.Name, workload.Selector))
+	c.podFilter = k8sutils.PodFilter{LabelSelector: workload.Selector}
+	ch := make(chan string)
+	c.podList = c.newPodList(ch)
+	ch <- c.currentNamespace
+	return true
+}
+
+// scaleWorkload prompts for a replica count and scales the selected
+// workload to it once confirmed
+func (c *controller) scaleWorkload() (q string) {
+	workload := c.getSelectedWorkload()
+	if workload == nil {
+		return
+	}
+	if workload.Kind == k8sutils.KindDaemonSet {
+		c.errorChan <- newErrorWithStack(fmt.Errorf("%s/%s can't be scaled", workload.Kind, workload.Name))
+		return
+	}
+	input := c.inputPrompt(
+		fmt.Sprintf(" Scale %s/%s to how many replicas? ", workload.Kind, workload.Name),
+		fmt.Sprintf("%d", workload.Desired),
+		unicode.IsDigit,
+	)
+	if input == _quit || input == _cancel {
+		return input
+	}
+	replicas, err := strconv.ParseInt(input, 10, 32)
+	if err != nil {
+		c.errorChan <- newErrorWithStack(err)
+		return
+	}
+	q = c.confirmPrompt(fmt.Sprintf("Scale %s/%s from %d to %d replicas?", workload.Kind, workload.Name, workload.Desired, replicas))
+	if q != confirmYes {
+		return
+	}
+	c.debug(fmt.Sprintf("Scaling %s/%s from %d to %d replicas", workload.Kind, workload.Name, workload.Desired, replicas))
+	if err = c.factory.ScaleWorkload(c.currentNamespace, workload.Kind, workload.Name, int32(replicas)); err != nil {
+		c.debug(fmt.Sprintf("Failed to scale %s/%s: %v", workload.Kind, workload.Name, err))
+		c.errorChan <- newErrorWithStack(err)
+		return ""
+	}
+	c.debug(fmt.Sprintf("Scaled %s/%s to %d replicas", workload.Kind, workload.Name, replicas))
+	c.workloadList = c.newWorkloadList()
+	return ""
+}
+
+// restartWorkload does a rollout restart of the selected workload once
+// confirmed
+func (c *controller) restartWorkload() (q string) {
+	workload := c.getSelectedWorkload()
+	if workload == nil {
+		return
+	}
+	if workload.Kind == k8sutils.KindReplicaSet {
+		c.errorChan <- newErrorWithStack(fmt.Errorf("%s/%s can't be restarted, restart its owner instead", workload.Kind, workload.Name))
+		return
+	}
+	q = c.confirmPrompt(fmt.Sprintf("Restart all pods of %s/%s?", workload.Kind, workload.Name))
+	if q != confirmYes {
+		return
+	}
+	c.debug(fmt.Sprintf("Restarting rollout of %s/%s", workload.Kind, workload.Name))
+	if err := c.factory.RestartWorkload(c.currentNamespace, workload.Kind, workload.Name); err != nil {
+		c.debug(fmt.Sprintf("Failed to restart %s/%s: %v", workload.Kind, workload.Name, err))
+		c.errorChan <- newErrorWithStack(err)
+		return ""
+	}
+	c.debug(fmt.Sprintf("Restarted rollout of %s/%s", workload.Kind, workload.Name))
+	c.workloadList = c.newWorkloadList()
+	return ""
+}
+
+func (c *controller) pollWorkloads() (next int) {
+	c.focusTab(workloadsTab)
+	c.helpWindow.Text = workloadsHelpText
+	c.workloadList = c.newWorkloadList()
+	uiEvents := c.events
+	for {
+		ui.Clear()
+		c.renderWorkloads()
+		e := <-uiEvents
+		switch e.ID {
+
+		// reload
+		case "r":
+			c.workloadList = c.newWorkloadList()
+
+		// get workload details
+		case enter:
+			c.selectWorkload()
+
+		case "s":
+			if q := c.scaleWorkload(); q == _quit {
+				return quitView
+			}
+
+		case "R":
+			if q := c.restartWorkload(); q == _quit {
+				return quitView
+			}
+
+		case "h":
+			if q := c.pollHistory(); q == _quit {
+				return quitView
+			}
+
+		// show the pods belonging to the workload
+		case "f":
+			if c.filterPodsByWorkload() {
+				return podsTab
+			}
+
+		default:
+			if next, ok := c.switchView(e.ID); ok {
+				return next
+			}
+			if q := c.checkCommon(c.workloadList, e.ID); q == _quit {
+				return quitView
+			}
+		}
+	}
+}